@@ -0,0 +1,547 @@
+package alert
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/bitmark-inc/logger"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/fault"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/messengers"
+)
+
+// Alert - condition of node which needs attention, empty severity is
+// warning, messengers are names of messengers to notify, routes choose them
+// when empty, chain and tags of node are matched by routes and silences
+type Alert struct {
+	Node       string
+	Condition  string
+	Message    string
+	Severity   string
+	Messengers []string
+	Chain      string
+	Tags       []string
+}
+
+// targets - alert is sent to messenger of name, alert without messenger
+// is sent to all
+func (a Alert) targets(name string) bool {
+	return 0 == len(a.Messengers) || contains(a.Messengers, name)
+}
+
+// severities - order of messages in one flush
+var severities = []string{
+	messengers.Critical,
+	messengers.Warning,
+	messengers.Info,
+}
+
+// Incident - alert kept open until its condition is resolved
+type Incident struct {
+	Alert
+	OpenedAt       time.Time
+	NotifiedAt     time.Time
+	AcknowledgedAt time.Time
+}
+
+// Acknowledged - someone is working on incident
+func (i Incident) Acknowledged() bool {
+	return !i.AcknowledgedAt.IsZero()
+}
+
+// Manager - keep open incidents and notify messenger
+type Manager interface {
+	Acknowledge(string, string) error
+	Fire(Alert)
+	Flush()
+	Incidents() []Incident
+	Loop([]interface{})
+	Notify(Alert)
+	Resolve(string, string)
+	ResolveWith(string, string, string)
+	Routes([]Route)
+	Schedule([]Window)
+	SetLog(*logger.L)
+	SetTimeSource(func() time.Time)
+	Silence(Silence)
+	Silences() []Silence
+	Unsilence(string) error
+}
+
+// notification - action is one of incident actions, empty for one-off alert
+type notification struct {
+	alert    Alert
+	action   string
+	duration time.Duration
+	detail   string
+}
+
+type manager struct {
+	sync.Mutex
+	cooldown       time.Duration
+	groupInterval  time.Duration
+	incidents      map[string]*Incident
+	log            *logger.L
+	messengers     map[string]messengers.Messenger
+	messengerNames []string
+	paging         map[string]messengers.IncidentMessenger
+	pagingNames    []string
+	now            func() time.Time
+	pending        []notification
+	routes         []Route
+	silences       map[string]Silence
+	windows        []Window
+}
+
+// NewManager - new alert manager of messengers and paging messengers keyed
+// by name, repeats of open incident are suppressed within cooldown,
+// notifications within group interval are sent together, incident changes
+// are sent to paging messengers one by one
+func NewManager(senders map[string]messengers.Messenger, paging map[string]messengers.IncidentMessenger, cooldown time.Duration, groupInterval time.Duration) Manager {
+	messengerNames := make([]string, 0, len(senders))
+	for name := range senders {
+		messengerNames = append(messengerNames, name)
+	}
+	sort.Strings(messengerNames)
+
+	pagingNames := make([]string, 0, len(paging))
+	for name := range paging {
+		pagingNames = append(pagingNames, name)
+	}
+	sort.Strings(pagingNames)
+
+	return &manager{
+		cooldown:       cooldown,
+		groupInterval:  groupInterval,
+		incidents:      make(map[string]*Incident),
+		messengers:     senders,
+		messengerNames: messengerNames,
+		now:            time.Now,
+		paging:         paging,
+		pagingNames:    pagingNames,
+		pending:        make([]notification, 0),
+		routes:         make([]Route, 0),
+		silences:       make(map[string]Silence),
+		windows:        make([]Window, 0),
+	}
+}
+
+func key(node string, condition string) string {
+	return fmt.Sprintf("%s/%s", node, condition)
+}
+
+// Fire - condition of node happens, notify when incident is new or cooldown
+// passed since last notification, acknowledged incident is not repeated,
+// silenced incident is kept and notified when silence ends
+func (m *manager) Fire(a Alert) {
+	m.Lock()
+	defer m.Unlock()
+
+	a, routed := m.routed(withSeverity(a))
+	now := m.now()
+	k := key(a.Node, a.Condition)
+	i, ok := m.incidents[k]
+	if !ok {
+		i = &Incident{OpenedAt: now}
+		m.incidents[k] = i
+	}
+	i.Alert = a
+
+	if ok && (i.Acknowledged() || now.Sub(i.NotifiedAt) < m.cooldown) {
+		return
+	}
+	if m.suppressed(a, now) {
+		return
+	}
+	if !routed {
+		m.dropped(a)
+		return
+	}
+	i.NotifiedAt = now
+	m.pending = append(m.pending, notification{
+		alert:  a,
+		action: messengers.Trigger,
+	})
+}
+
+// Acknowledge - someone takes open incident of node, stop repeating it
+func (m *manager) Acknowledge(node string, condition string) error {
+	m.Lock()
+	defer m.Unlock()
+
+	i, ok := m.incidents[key(node, condition)]
+	if !ok {
+		return fault.UnknownIncident
+	}
+	if i.Acknowledged() {
+		return nil
+	}
+
+	now := m.now()
+	i.AcknowledgedAt = now
+	if i.NotifiedAt.IsZero() {
+		return nil
+	}
+	m.pending = append(m.pending, notification{
+		alert:    i.Alert,
+		action:   messengers.Acknowledge,
+		duration: now.Sub(i.OpenedAt),
+	})
+	return nil
+}
+
+// Notify - one-off alert without incident, dropped when silenced
+func (m *manager) Notify(a Alert) {
+	m.Lock()
+	defer m.Unlock()
+
+	if m.suppressed(a, m.now()) {
+		return
+	}
+
+	a, routed := m.routed(withSeverity(a))
+	if !routed {
+		m.dropped(a)
+		return
+	}
+	m.pending = append(m.pending, notification{alert: a})
+}
+
+func withSeverity(a Alert) Alert {
+	if "" == a.Severity {
+		a.Severity = messengers.Warning
+	}
+	return a
+}
+
+// routed - caller holds lock, alert without messenger is sent to
+// messengers of matching routes, false when routes are set and none
+// matches, all messengers are notified when no route is set
+func (m *manager) routed(a Alert) (Alert, bool) {
+	if 0 < len(a.Messengers) || 0 == len(m.routes) {
+		return a, true
+	}
+
+	a.Messengers = destinations(m.routes, a)
+	return a, 0 < len(a.Messengers)
+}
+
+// dropped - caller holds lock, alert matching no route goes to event log
+// only, stdout before log is set
+func (m *manager) dropped(a Alert) {
+	if nil == m.log {
+		fmt.Printf("no route of alert %s, drop: %s\n", key(a.Node, a.Condition), a.Message)
+	} else {
+		m.log.Warnf("no route of alert %s, drop: %s", key(a.Node, a.Condition), a.Message)
+	}
+}
+
+// SetLog - set event log of manager, suppressed alerts are written to it
+func (m *manager) SetLog(log *logger.L) {
+	m.Lock()
+	defer m.Unlock()
+
+	m.log = log
+}
+
+// SetTimeSource - set function manager uses to get current time, replay
+// drives cooldown and silences by capture time
+func (m *manager) SetTimeSource(source func() time.Time) {
+	m.Lock()
+	defer m.Unlock()
+
+	m.now = source
+}
+
+// Routes - replace routes of alerts
+func (m *manager) Routes(routes []Route) {
+	m.Lock()
+	defer m.Unlock()
+
+	m.routes = routes
+}
+
+// Resolve - condition of node clears, notify if incident was open
+func (m *manager) Resolve(node string, condition string) {
+	m.ResolveWith(node, condition, "")
+}
+
+// ResolveWith - condition of node clears, detail follows resolution in
+// message
+func (m *manager) ResolveWith(node string, condition string, detail string) {
+	m.Lock()
+	defer m.Unlock()
+
+	k := key(node, condition)
+	i, ok := m.incidents[k]
+	if !ok {
+		return
+	}
+	delete(m.incidents, k)
+
+	// nobody knows incident opened during silence
+	if i.NotifiedAt.IsZero() {
+		return
+	}
+
+	m.pending = append(m.pending, notification{
+		alert:    i.Alert,
+		action:   messengers.Resolve,
+		duration: m.now().Sub(i.OpenedAt),
+		detail:   detail,
+	})
+}
+
+// Silence - suppress matching alerts until end of silence, silence of same
+// name is replaced
+func (m *manager) Silence(s Silence) {
+	m.Lock()
+	defer m.Unlock()
+
+	m.silences[s.Name] = s
+}
+
+// Unsilence - lift silence of name, weekly window cannot be lifted
+func (m *manager) Unsilence(name string) error {
+	m.Lock()
+	defer m.Unlock()
+
+	if _, ok := m.silences[name]; !ok {
+		return fault.UnknownSilence
+	}
+	delete(m.silences, name)
+	return nil
+}
+
+// Schedule - replace weekly windows
+func (m *manager) Schedule(windows []Window) {
+	m.Lock()
+	defer m.Unlock()
+
+	m.windows = windows
+}
+
+// Silences - silences active now, sorted by name
+func (m *manager) Silences() []Silence {
+	m.Lock()
+	defer m.Unlock()
+
+	result := m.active(m.now())
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// active - caller holds lock, expired silence is removed
+func (m *manager) active(now time.Time) []Silence {
+	result := make([]Silence, 0, len(m.silences))
+	for name, s := range m.silences {
+		if !now.Before(s.End) {
+			delete(m.silences, name)
+			continue
+		}
+		if s.active(now) {
+			result = append(result, s)
+		}
+	}
+
+	for _, w := range m.windows {
+		if s := w.At(now); s.active(now) {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// suppressed - caller holds lock, suppressed alert goes to event log only,
+// stdout before log is set
+func (m *manager) suppressed(a Alert, now time.Time) bool {
+	for _, s := range m.active(now) {
+		if !s.Matches(a.Node, a.Tags, a.Condition) {
+			continue
+		}
+
+		if nil == m.log {
+			fmt.Printf("silence %s suppress alert %s: %s\n", s.Name, key(a.Node, a.Condition), a.Message)
+		} else {
+			m.log.Infof("silence %s suppress alert %s: %s", s.Name, key(a.Node, a.Condition), a.Message)
+		}
+		return true
+	}
+	return false
+}
+
+// Incidents - open incidents sorted by node and condition
+func (m *manager) Incidents() []Incident {
+	m.Lock()
+	defer m.Unlock()
+
+	result := make([]Incident, 0, len(m.incidents))
+	for _, i := range m.incidents {
+		result = append(result, *i)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return key(result[i].Node, result[i].Condition) < key(result[j].Node, result[j].Condition)
+	})
+	return result
+}
+
+// Loop - send grouped notifications every group interval
+func (m *manager) Loop(args []interface{}) {
+	if 1 != len(args) {
+		fmt.Println("alert manager Loop wrong argument length")
+		return
+	}
+	shutdown := args[0].(<-chan struct{})
+	timer := time.NewTimer(m.groupInterval)
+
+	for {
+		select {
+		case <-shutdown:
+			m.Flush()
+			fmt.Println("terminate alert manager loop")
+			return
+
+		case <-timer.C:
+			m.Flush()
+			timer.Reset(m.groupInterval)
+		}
+	}
+}
+
+// Flush - send pending notifications now, loop flushes every group
+// interval
+func (m *manager) Flush() {
+	m.Lock()
+	pending := m.pending
+	m.pending = make([]notification, 0)
+	m.Unlock()
+
+	if 0 == len(pending) {
+		return
+	}
+
+	m.page(pending)
+
+	sent := false
+	for _, name := range m.messengerNames {
+		messenger := m.messengers[name]
+		if !messenger.Valid() {
+			continue
+		}
+		sent = true
+
+		targeted := targetedTo(pending, name)
+		for _, severity := range severities {
+			send(messenger, severity, bySeverity(targeted, severity))
+		}
+	}
+
+	if !sent {
+		fmt.Printf("no valid messenger, drop alert: %s\n", group(pending))
+	}
+}
+
+// send - one grouped message of notifications with same severity, events of
+// notifications follow so messenger can thread follow-ups of incident
+func send(messenger messengers.Messenger, severity string, pending []notification) {
+	if 0 == len(pending) {
+		return
+	}
+
+	msg := group(pending)
+	events := make([]messengers.IncidentEvent, 0, len(pending))
+	for _, n := range pending {
+		events = append(events, n.event())
+	}
+
+	if err := messenger.Send(msg, severity, events); nil != err {
+		fmt.Printf("send alert %s with error: %s\n", msg, err)
+	}
+}
+
+// targetedTo - notifications sent to messenger of name
+func targetedTo(pending []notification, name string) []notification {
+	result := make([]notification, 0, len(pending))
+	for _, n := range pending {
+		if n.alert.targets(name) {
+			result = append(result, n)
+		}
+	}
+	return result
+}
+
+// bySeverity - notifications of severity, acknowledged and resolved
+// incidents are info
+func bySeverity(pending []notification, severity string) []notification {
+	result := make([]notification, 0, len(pending))
+	for _, n := range pending {
+		s := n.alert.Severity
+		if n.followsUp() {
+			s = messengers.Info
+		}
+		if s == severity {
+			result = append(result, n)
+		}
+	}
+	return result
+}
+
+// page - send incident changes one by one, dedup key of same node and
+// condition is stable so paging service opens and closes same incident
+func (m *manager) page(pending []notification) {
+	for _, name := range m.pagingNames {
+		paging := m.paging[name]
+		if !paging.Valid() {
+			continue
+		}
+
+		for _, n := range targetedTo(pending, name) {
+			if "" == n.action {
+				continue
+			}
+
+			if err := paging.Incident(n.event()); nil != err {
+				fmt.Printf("page %s of %s with error: %s\n", n.action, key(n.alert.Node, n.alert.Condition), err)
+			}
+		}
+	}
+}
+
+// group - one message of notifications, same condition of many nodes share
+// one section
+func group(pending []notification) string {
+	events := make([]messengers.IncidentEvent, 0, len(pending))
+	for _, n := range pending {
+		events = append(events, n.event())
+	}
+	return messengers.Group(events)
+}
+
+// event - incident event of notification, action is empty for one-off alert
+func (n notification) event() messengers.IncidentEvent {
+	return messengers.IncidentEvent{
+		Action:    n.action,
+		Key:       key(n.alert.Node, n.alert.Condition),
+		Node:      n.alert.Node,
+		Condition: n.alert.Condition,
+		Summary:   line(n),
+	}
+}
+
+// followsUp - notification acknowledges or resolves open incident
+func (n notification) followsUp() bool {
+	return messengers.Acknowledge == n.action || messengers.Resolve == n.action
+}
+
+func line(n notification) string {
+	if n.followsUp() && "" != n.detail {
+		return fmt.Sprintf("%s %s %s after %s: %s", n.alert.Node, n.alert.Condition, messengers.Past(n.action), n.duration.Truncate(time.Second), n.detail)
+	}
+	if n.followsUp() {
+		return fmt.Sprintf("%s %s %s after %s", n.alert.Node, n.alert.Condition, messengers.Past(n.action), n.duration.Truncate(time.Second))
+	}
+	return fmt.Sprintf("%s %s", n.alert.Node, n.alert.Message)
+}
@@ -0,0 +1,339 @@
+package alert
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bitmark-inc/logger"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/configuration"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/fault"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/messengers"
+	"github.com/stretchr/testify/assert"
+)
+
+type testMessenger struct {
+	messages   []string
+	severities []string
+}
+
+func (t *testMessenger) Send(args ...interface{}) error {
+	t.messages = append(t.messages, fmt.Sprintf("%s", args[0]))
+	t.severities = append(t.severities, fmt.Sprintf("%s", args[1]))
+	return nil
+}
+
+func (t *testMessenger) Valid() bool {
+	return true
+}
+
+type testPaging struct {
+	events []messengers.IncidentEvent
+}
+
+func (t *testPaging) Incident(e messengers.IncidentEvent) error {
+	t.events = append(t.events, e)
+	return nil
+}
+
+func (t *testPaging) Valid() bool {
+	return true
+}
+
+type testClock struct {
+	current time.Time
+}
+
+func (t *testClock) now() time.Time {
+	return t.current
+}
+
+func setupManager() (*manager, *testMessenger, *testClock) {
+	messenger := &testMessenger{}
+	clk := &testClock{current: time.Now()}
+	m := NewManager(
+		map[string]messengers.Messenger{"test": messenger},
+		nil,
+		30*time.Minute,
+		10*time.Second,
+	).(*manager)
+	m.SetTimeSource(clk.now)
+	return m, messenger, clk
+}
+
+func TestFireWhenRepeatedInCooldown(t *testing.T) {
+	m, messenger, clk := setupManager()
+
+	m.Fire(Alert{Node: "a", Condition: "lag", Message: "5 blocks behind"})
+	m.Flush()
+	assert.Equal(t, []string{"a 5 blocks behind"}, messenger.messages, "wrong first message")
+
+	clk.current = clk.current.Add(10 * time.Minute)
+	m.Fire(Alert{Node: "a", Condition: "lag", Message: "6 blocks behind"})
+	m.Flush()
+	assert.Equal(t, 1, len(messenger.messages), "wrong repeat in cooldown")
+
+	clk.current = clk.current.Add(30 * time.Minute)
+	m.Fire(Alert{Node: "a", Condition: "lag", Message: "7 blocks behind"})
+	m.Flush()
+	assert.Equal(t, 2, len(messenger.messages), "wrong repeat after cooldown")
+	assert.Equal(t, "a 7 blocks behind", messenger.messages[1], "wrong repeat message")
+}
+
+func TestResolve(t *testing.T) {
+	m, messenger, clk := setupManager()
+
+	m.Resolve("a", "lag")
+	m.Flush()
+	assert.Equal(t, 0, len(messenger.messages), "wrong resolve without incident")
+
+	m.Fire(Alert{Node: "a", Condition: "lag", Message: "5 blocks behind"})
+	m.Flush()
+	assert.Equal(t, 1, len(m.Incidents()), "wrong incident count")
+
+	clk.current = clk.current.Add(5 * time.Minute)
+	m.Resolve("a", "lag")
+	m.Flush()
+	assert.Equal(t, 0, len(m.Incidents()), "wrong incident not closed")
+	assert.Equal(t, "a lag resolved after 5m0s", messenger.messages[1], "wrong resolved message")
+
+	m.Fire(Alert{Node: "a", Condition: "lag", Message: "5 blocks behind"})
+	m.Flush()
+	assert.Equal(t, 3, len(messenger.messages), "wrong new incident after resolved")
+}
+
+func TestResolveWith(t *testing.T) {
+	m, messenger, clk := setupManager()
+
+	m.Fire(Alert{Node: "a", Condition: "node-silent", Message: "no heartbeat for 3m0s"})
+	clk.current = clk.current.Add(2 * time.Minute)
+	m.ResolveWith("a", "node-silent", "heartbeat back after 5m0s of silence")
+	m.Flush()
+
+	assert.Equal(t, 0, len(m.Incidents()), "wrong incident not closed")
+	assert.Equal(t, 2, len(messenger.messages), "wrong message count")
+	assert.Equal(t, "a node-silent resolved after 2m0s: heartbeat back after 5m0s of silence", messenger.messages[1], "wrong resolved message")
+}
+
+func TestFlushGroupsNodes(t *testing.T) {
+	m, messenger, _ := setupManager()
+
+	m.Fire(Alert{Node: "a", Condition: "lag", Message: "5 blocks behind"})
+	m.Fire(Alert{Node: "b", Condition: "lag", Message: "6 blocks behind"})
+	m.Fire(Alert{Node: "a", Condition: "not-normal", Message: "not in normal mode"})
+	m.Notify(Alert{Node: "c", Condition: "version", Message: "version changed"})
+	m.Flush()
+
+	expected := "lag on 2 nodes:\n  a 5 blocks behind\n  b 6 blocks behind\na not in normal mode\nc version changed"
+	assert.Equal(t, []string{expected}, messenger.messages, "wrong grouped message")
+
+	m.Flush()
+	assert.Equal(t, 1, len(messenger.messages), "wrong empty flush")
+}
+
+func TestLoopFlushesWhenShutdown(t *testing.T) {
+	m, messenger, _ := setupManager()
+	shutdown := make(chan struct{})
+	done := make(chan struct{})
+
+	go func() {
+		m.Loop([]interface{}{(<-chan struct{})(shutdown)})
+		close(done)
+	}()
+
+	m.Fire(Alert{Node: "a", Condition: "lag", Message: "5 blocks behind"})
+	close(shutdown)
+	<-done
+
+	assert.Equal(t, 1, len(messenger.messages), "wrong message count")
+}
+
+func TestAcknowledge(t *testing.T) {
+	m, messenger, clk := setupManager()
+
+	err := m.Acknowledge("a", "lag")
+	m.Flush()
+	assert.Equal(t, fault.UnknownIncident, err, "wrong error")
+	assert.Equal(t, 0, len(messenger.messages), "wrong acknowledge without incident")
+
+	m.Fire(Alert{Node: "a", Condition: "lag", Message: "5 blocks behind"})
+	m.Flush()
+
+	clk.current = clk.current.Add(2 * time.Minute)
+	assert.Nil(t, m.Acknowledge("a", "lag"), "wrong error")
+	assert.Nil(t, m.Acknowledge("a", "lag"), "wrong error of acknowledged incident")
+	m.Flush()
+	assert.Equal(t, 2, len(messenger.messages), "wrong acknowledge count")
+	assert.Equal(t, "a lag acknowledged after 2m0s", messenger.messages[1], "wrong acknowledged message")
+	assert.True(t, m.Incidents()[0].Acknowledged(), "wrong incident not acknowledged")
+
+	clk.current = clk.current.Add(time.Hour)
+	m.Fire(Alert{Node: "a", Condition: "lag", Message: "7 blocks behind"})
+	m.Flush()
+	assert.Equal(t, 2, len(messenger.messages), "wrong repeat of acknowledged incident")
+}
+
+func TestPageIncidentChanges(t *testing.T) {
+	paging := &testPaging{}
+	m := NewManager(nil, map[string]messengers.IncidentMessenger{"paging": paging}, time.Hour, time.Second).(*manager)
+
+	m.Fire(Alert{Node: "a", Condition: "fork", Message: "split at block 10"})
+	m.Notify(Alert{Node: "a", Condition: "version", Message: "version changed"})
+	m.Acknowledge("a", "fork")
+	m.Resolve("a", "fork")
+	m.Flush()
+
+	expected := []messengers.IncidentEvent{
+		{Action: messengers.Trigger, Key: "a/fork", Node: "a", Condition: "fork", Summary: "a split at block 10"},
+		{Action: messengers.Acknowledge, Key: "a/fork", Node: "a", Condition: "fork", Summary: "a fork acknowledged after 0s"},
+		{Action: messengers.Resolve, Key: "a/fork", Node: "a", Condition: "fork", Summary: "a fork resolved after 0s"},
+	}
+	assert.Equal(t, expected, paging.events, "wrong incident events")
+}
+
+func TestFlushBySeverity(t *testing.T) {
+	m, messenger, _ := setupManager()
+
+	m.Fire(Alert{Node: "a", Condition: "drop-rate", Message: "drop rate 10%"})
+	m.Fire(Alert{Node: "a", Condition: "fork", Message: "split at block 10", Severity: messengers.Critical})
+	m.Flush()
+
+	m.Resolve("a", "fork")
+	m.Flush()
+
+	expected := []string{"a split at block 10", "a drop rate 10%", "a fork resolved after 0s"}
+	assert.Equal(t, expected, messenger.messages, "wrong messages")
+	assert.Equal(t, []string{"critical", "warning", "info"}, messenger.severities, "wrong severities")
+}
+
+func TestSilence(t *testing.T) {
+	m, messenger, clk := setupManager()
+
+	m.Silence(Silence{Name: "a", Nodes: []string{"a"}, Start: clk.current, End: clk.current.Add(time.Hour)})
+	assert.Equal(t, 1, len(m.Silences()), "wrong silences")
+
+	m.Fire(Alert{Node: "a", Condition: "lag", Message: "5 blocks behind"})
+	m.Notify(Alert{Node: "a", Condition: "version", Message: "version changed"})
+	m.Fire(Alert{Node: "b", Condition: "lag", Message: "6 blocks behind"})
+	m.Flush()
+	assert.Equal(t, []string{"b 6 blocks behind"}, messenger.messages, "wrong messages in silence")
+	assert.Equal(t, 2, len(m.Incidents()), "wrong incidents in silence")
+
+	clk.current = clk.current.Add(time.Hour)
+	assert.Equal(t, 0, len(m.Silences()), "wrong silences after expired")
+
+	m.Fire(Alert{Node: "a", Condition: "lag", Message: "7 blocks behind"})
+	m.Flush()
+	assert.Equal(t, "a 7 blocks behind", messenger.messages[1], "wrong message after silence")
+}
+
+func TestSilenceOfTagsAndConditions(t *testing.T) {
+	m, messenger, clk := setupManager()
+
+	m.Silence(Silence{
+		Name:       "upgrade",
+		Tags:       []string{"mainnet"},
+		Conditions: []string{"blocks", "drop-*"},
+		Start:      clk.current,
+		End:        clk.current.Add(time.Hour),
+	})
+
+	m.Fire(Alert{Node: "a", Condition: "blocks", Message: "missing blocks", Tags: []string{"mainnet"}})
+	m.Fire(Alert{Node: "a", Condition: "drop-rate", Message: "drop rate 20%", Tags: []string{"mainnet"}})
+	m.Fire(Alert{Node: "a", Condition: "lag", Message: "5 blocks behind", Tags: []string{"mainnet"}})
+	m.Fire(Alert{Node: "b", Condition: "blocks", Message: "missing blocks", Tags: []string{"testnet"}})
+	m.Flush()
+
+	assert.Equal(t, []string{"a 5 blocks behind\nb missing blocks"}, messenger.messages, "wrong messages")
+}
+
+func TestUnsilence(t *testing.T) {
+	m, messenger, clk := setupManager()
+
+	assert.Equal(t, fault.UnknownSilence, m.Unsilence("a"), "wrong error")
+
+	m.Silence(Silence{Name: "a", Nodes: []string{"a"}, Start: clk.current, End: clk.current.Add(time.Hour)})
+	m.Fire(Alert{Node: "a", Condition: "lag", Message: "5 blocks behind"})
+	m.Resolve("a", "lag")
+	m.Flush()
+	assert.Equal(t, 0, len(messenger.messages), "wrong resolve of incident never notified")
+	assert.Equal(t, 0, len(m.Incidents()), "wrong incident not closed")
+
+	assert.Nil(t, m.Unsilence("a"), "wrong error")
+	assert.Equal(t, 0, len(m.Silences()), "wrong silence not lifted")
+}
+
+func TestSchedule(t *testing.T) {
+	m, messenger, clk := setupManager()
+
+	// tuesday
+	clk.current = time.Date(2019, time.December, 3, 3, 0, 0, 0, time.UTC)
+	w, _ := NewWindow(configuration.SilenceConfig{
+		Name:           "upgrade",
+		Weekday:        "tuesday",
+		Start:          "02:00",
+		DurationMinute: 120,
+	})
+	m.Schedule([]Window{w})
+
+	assert.Equal(t, 1, len(m.Silences()), "wrong silences in window")
+	m.Fire(Alert{Node: "a", Condition: "lag", Message: "5 blocks behind"})
+	m.Flush()
+	assert.Equal(t, 0, len(messenger.messages), "wrong messages in window")
+
+	clk.current = clk.current.Add(time.Hour)
+	assert.Equal(t, 0, len(m.Silences()), "wrong silences after window")
+}
+
+func TestFlushToTargetedMessengers(t *testing.T) {
+	messenger := &testMessenger{}
+	other := &testMessenger{}
+	paging := &testPaging{}
+	m := NewManager(
+		map[string]messengers.Messenger{"test": messenger, "other": other},
+		map[string]messengers.IncidentMessenger{"paging": paging},
+		time.Hour,
+		time.Second,
+	).(*manager)
+
+	m.Fire(Alert{Node: "a", Condition: "lag", Message: "5 blocks behind", Messengers: []string{"other", "paging"}})
+	m.Fire(Alert{Node: "b", Condition: "lag", Message: "6 blocks behind", Messengers: []string{"test"}})
+	m.Notify(Alert{Node: "c", Condition: "version", Message: "version changed"})
+	m.Flush()
+
+	assert.Equal(t, []string{"b 6 blocks behind\nc version changed"}, messenger.messages, "wrong test messages")
+	assert.Equal(t, []string{"a 5 blocks behind\nc version changed"}, other.messages, "wrong other messages")
+	assert.Equal(t, 1, len(paging.events), "wrong paging events")
+	assert.Equal(t, "a/lag", paging.events[0].Key, "wrong paging incident")
+}
+
+func TestSilenceWritesSuppressedToLog(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "alert")
+	defer os.RemoveAll(dir)
+
+	err := logger.Initialise(logger.Configuration{
+		Directory: dir,
+		File:      "alert.log",
+		Size:      1048576,
+		Count:     10,
+		Levels:    map[string]string{logger.DefaultTag: "info"},
+	})
+	if nil != err {
+		t.Fatalf("initialise logger with error: %s", err)
+	}
+	defer logger.Finalise()
+
+	m, _, clk := setupManager()
+	m.SetLog(logger.New("alert"))
+
+	m.Silence(Silence{Name: "upgrade", Nodes: []string{"a"}, Start: clk.current, End: clk.current.Add(time.Hour)})
+	m.Fire(Alert{Node: "a", Condition: "lag", Message: "5 blocks behind"})
+	logger.Flush()
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "alert.log"))
+	assert.Nil(t, err, "wrong log file")
+	assert.Contains(t, string(data), "silence upgrade suppress alert a/lag: 5 blocks behind", "wrong suppressed alert log")
+}
@@ -0,0 +1,103 @@
+package alert
+
+import (
+	"path"
+
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/configuration"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/fault"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/messengers"
+)
+
+// Route - alerts of selected severities, nodes, chains and tags are sent to
+// messengers, empty selector selects all, nodes are name patterns
+type Route struct {
+	Messengers []string
+	Severities []string
+	Nodes      []string
+	Chains     []string
+	Tags       []string
+	Continue   bool
+}
+
+// NewRoute - route of config, route without messenger or with unknown
+// severity is invalid
+func NewRoute(config configuration.RouteConfig) (Route, error) {
+	if 0 == len(config.Messengers) {
+		return Route{}, fault.InvalidRoute
+	}
+
+	for _, s := range config.Severities {
+		if messengers.Critical != s && messengers.Warning != s && messengers.Info != s {
+			return Route{}, fault.InvalidRoute
+		}
+	}
+
+	for _, pattern := range config.Nodes {
+		if _, err := path.Match(pattern, ""); nil != err {
+			return Route{}, err
+		}
+	}
+
+	return Route{
+		Messengers: config.Messengers,
+		Severities: config.Severities,
+		Nodes:      config.Nodes,
+		Chains:     config.Chains,
+		Tags:       config.Tags,
+		Continue:   config.Continue,
+	}, nil
+}
+
+func (r Route) matches(a Alert) bool {
+	if 0 < len(r.Severities) && !contains(r.Severities, a.Severity) {
+		return false
+	}
+
+	if 0 < len(r.Nodes) && !anyMatch(r.Nodes, a.Node) {
+		return false
+	}
+
+	if 0 < len(r.Chains) && !contains(r.Chains, a.Chain) {
+		return false
+	}
+
+	if 0 == len(r.Tags) {
+		return true
+	}
+	for _, t := range a.Tags {
+		if contains(r.Tags, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// destinations - messengers of matching routes, empty when none matches
+func destinations(routes []Route, a Alert) []string {
+	result := make([]string, 0)
+	for _, r := range routes {
+		if !r.matches(a) {
+			continue
+		}
+
+		for _, m := range r.Messengers {
+			if !contains(result, m) {
+				result = append(result, m)
+			}
+		}
+
+		if !r.Continue {
+			break
+		}
+	}
+	return result
+}
+
+func contains(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,124 @@
+package alert
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/configuration"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/fault"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/messengers"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRouteWhenInvalid(t *testing.T) {
+	_, err := NewRoute(configuration.RouteConfig{})
+	assert.Equal(t, fault.InvalidRoute, err, "wrong error without messenger")
+
+	_, err = NewRoute(configuration.RouteConfig{
+		Messengers: []string{"slack"},
+		Severities: []string{"urgent"},
+	})
+	assert.Equal(t, fault.InvalidRoute, err, "wrong error of severity")
+
+	_, err = NewRoute(configuration.RouteConfig{
+		Messengers: []string{"slack"},
+		Nodes:      []string{"["},
+	})
+	assert.NotNil(t, err, "wrong error of node pattern")
+}
+
+func TestDestinations(t *testing.T) {
+	routes := []Route{
+		{Messengers: []string{"oncall"}, Severities: []string{"critical"}, Chains: []string{"bitmark"}, Continue: true},
+		{Messengers: []string{"mainnet"}, Chains: []string{"bitmark"}},
+		{Messengers: []string{"testnet"}, Chains: []string{"testing"}},
+		{Messengers: []string{"db"}, Nodes: []string{"db*"}, Tags: []string{"storage"}},
+	}
+
+	tests := []struct {
+		alert    Alert
+		expected []string
+	}{
+		{Alert{Node: "a", Severity: "critical", Chain: "bitmark"}, []string{"oncall", "mainnet"}},
+		{Alert{Node: "a", Severity: "warning", Chain: "bitmark"}, []string{"mainnet"}},
+		{Alert{Node: "b", Severity: "critical", Chain: "testing"}, []string{"testnet"}},
+		{Alert{Node: "db1", Tags: []string{"storage"}}, []string{"db"}},
+		{Alert{Node: "db1", Tags: []string{"cache"}}, []string{}},
+	}
+
+	for i, test := range tests {
+		assert.Equal(t, test.expected, destinations(routes, test.alert), "wrong destinations of test %d", i)
+	}
+}
+
+func TestFlushByRoutes(t *testing.T) {
+	mainnet := &testMessenger{}
+	testnet := &testMessenger{}
+	m := NewManager(
+		map[string]messengers.Messenger{"mainnet": mainnet, "testnet": testnet},
+		nil,
+		time.Hour,
+		time.Second,
+	).(*manager)
+	m.Routes([]Route{
+		{Messengers: []string{"mainnet"}, Chains: []string{"bitmark"}},
+		{Messengers: []string{"testnet"}, Chains: []string{"testing"}},
+	})
+
+	m.Fire(Alert{Node: "a", Condition: "lag", Message: "5 blocks behind", Chain: "bitmark"})
+	m.Fire(Alert{Node: "b", Condition: "lag", Message: "6 blocks behind", Chain: "testing"})
+	m.Fire(Alert{Node: "c", Condition: "lag", Message: "7 blocks behind", Chain: "testing", Messengers: []string{"mainnet"}})
+	m.Notify(Alert{Node: "fleet", Condition: "version-drift", Message: "version drift"})
+	m.Flush()
+
+	assert.Equal(t, []string{"lag on 2 nodes:\n  a 5 blocks behind\n  c 7 blocks behind"}, mainnet.messages, "wrong mainnet messages")
+	assert.Equal(t, []string{"b 6 blocks behind"}, testnet.messages, "wrong testnet messages")
+
+	m.Resolve("b", "lag")
+	m.Flush()
+	assert.Equal(t, 1, len(mainnet.messages), "wrong resolve to mainnet")
+	assert.Equal(t, "b lag resolved after 0s", testnet.messages[1], "wrong resolve to testnet")
+}
+
+func TestFlushDropsAlertMatchingNoRoute(t *testing.T) {
+	mainnet := &testMessenger{}
+	testnet := &testMessenger{}
+	m := NewManager(
+		map[string]messengers.Messenger{"mainnet": mainnet, "testnet": testnet},
+		nil,
+		time.Hour,
+		time.Second,
+	).(*manager)
+	m.Routes([]Route{
+		{Messengers: []string{"mainnet"}, Chains: []string{"bitmark"}},
+	})
+
+	m.Fire(Alert{Node: "b", Condition: "lag", Message: "6 blocks behind", Chain: "testing"})
+	m.Notify(Alert{Node: "fleet", Condition: "version-drift", Message: "version drift"})
+	m.Flush()
+
+	assert.Equal(t, 0, len(mainnet.messages), "wrong unmatched alert to mainnet")
+	assert.Equal(t, 0, len(testnet.messages), "wrong unmatched alert to testnet")
+	assert.Equal(t, 1, len(m.Incidents()), "wrong incident of unmatched alert")
+
+	m.Resolve("b", "lag")
+	m.Flush()
+	assert.Equal(t, 0, len(mainnet.messages), "wrong resolve of unmatched alert")
+}
+
+func TestFlushWithoutRoutes(t *testing.T) {
+	mainnet := &testMessenger{}
+	testnet := &testMessenger{}
+	m := NewManager(
+		map[string]messengers.Messenger{"mainnet": mainnet, "testnet": testnet},
+		nil,
+		time.Hour,
+		time.Second,
+	).(*manager)
+
+	m.Fire(Alert{Node: "b", Condition: "lag", Message: "6 blocks behind", Chain: "testing"})
+	m.Flush()
+
+	assert.Equal(t, []string{"b 6 blocks behind"}, mainnet.messages, "wrong mainnet messages")
+	assert.Equal(t, []string{"b 6 blocks behind"}, testnet.messages, "wrong testnet messages")
+}
@@ -0,0 +1,173 @@
+package alert
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/configuration"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/fault"
+)
+
+const (
+	week = 7 * 24 * time.Hour
+)
+
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+// Silence - suppress alerts of selected nodes, tags and conditions from
+// start until end, empty selector selects all, nodes and conditions are
+// name patterns
+type Silence struct {
+	Name       string
+	Nodes      []string
+	Tags       []string
+	Conditions []string
+	Start      time.Time
+	End        time.Time
+}
+
+// Matches - alert of node with tags and condition is suppressed by silence
+func (s Silence) Matches(node string, tags []string, condition string) bool {
+	if 0 < len(s.Nodes) && !anyMatch(s.Nodes, node) {
+		return false
+	}
+
+	if 0 < len(s.Conditions) && !anyMatch(s.Conditions, condition) {
+		return false
+	}
+
+	if 0 == len(s.Tags) {
+		return true
+	}
+	for _, t := range tags {
+		for _, selected := range s.Tags {
+			if t == selected {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// String - name and selectors of silence
+func (s Silence) String() string {
+	selectors := make([]string, 0, 3)
+	if 0 < len(s.Nodes) {
+		selectors = append(selectors, "nodes "+strings.Join(s.Nodes, ", "))
+	}
+	if 0 < len(s.Tags) {
+		selectors = append(selectors, "tags "+strings.Join(s.Tags, ", "))
+	}
+	if 0 < len(s.Conditions) {
+		selectors = append(selectors, "alerts "+strings.Join(s.Conditions, ", "))
+	}
+
+	if 0 == len(selectors) {
+		return s.Name + " of all"
+	}
+	return fmt.Sprintf("%s of %s", s.Name, strings.Join(selectors, "; "))
+}
+
+func (s Silence) active(now time.Time) bool {
+	return !now.Before(s.Start) && now.Before(s.End)
+}
+
+func anyMatch(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// Window - silence repeated every week, e.g. upgrade on tuesday 02:00 for
+// two hours
+type Window struct {
+	name       string
+	weekday    time.Weekday
+	start      time.Duration
+	duration   time.Duration
+	location   *time.Location
+	nodes      []string
+	tags       []string
+	conditions []string
+}
+
+// NewWindow - weekly window of config, empty timezone is UTC
+func NewWindow(config configuration.SilenceConfig) (Window, error) {
+	if "" == config.Name {
+		return Window{}, fault.InvalidSilenceName
+	}
+
+	weekday, ok := weekdays[strings.ToLower(config.Weekday)]
+	if !ok {
+		return Window{}, fault.InvalidSilenceWeekday
+	}
+
+	start, err := time.Parse("15:04", config.Start)
+	if nil != err {
+		return Window{}, fault.InvalidSilenceStart
+	}
+
+	duration := time.Duration(config.DurationMinute) * time.Minute
+	if 0 >= duration || week < duration {
+		return Window{}, fault.InvalidSilenceDuration
+	}
+
+	location, err := time.LoadLocation(config.Timezone)
+	if nil != err {
+		return Window{}, err
+	}
+
+	for _, patterns := range [][]string{config.Nodes, config.Conditions} {
+		for _, pattern := range patterns {
+			if _, err := path.Match(pattern, ""); nil != err {
+				return Window{}, err
+			}
+		}
+	}
+
+	return Window{
+		name:       config.Name,
+		weekday:    weekday,
+		start:      time.Duration(start.Hour())*time.Hour + time.Duration(start.Minute())*time.Minute,
+		duration:   duration,
+		location:   location,
+		nodes:      config.Nodes,
+		tags:       config.Tags,
+		conditions: config.Conditions,
+	}, nil
+}
+
+// At - silence of latest window started before time, window is open when
+// silence is active at time
+func (w Window) At(now time.Time) Silence {
+	local := now.In(w.location)
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, w.location)
+	days := (int(local.Weekday()) - int(w.weekday) + 7) % 7
+
+	start := midnight.AddDate(0, 0, -days).Add(w.start)
+	if start.After(local) {
+		start = start.AddDate(0, 0, -7)
+	}
+
+	return Silence{
+		Name:       w.name,
+		Nodes:      w.nodes,
+		Tags:       w.tags,
+		Conditions: w.conditions,
+		Start:      start,
+		End:        start.Add(w.duration),
+	}
+}
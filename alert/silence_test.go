@@ -0,0 +1,82 @@
+package alert
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/configuration"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/fault"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSilenceMatches(t *testing.T) {
+	s := Silence{
+		Nodes:      []string{"node*"},
+		Tags:       []string{"mainnet"},
+		Conditions: []string{"blocks"},
+	}
+
+	assert.True(t, s.Matches("node1", []string{"testnet", "mainnet"}, "blocks"), "wrong match")
+	assert.False(t, s.Matches("other", []string{"mainnet"}, "blocks"), "wrong match of node")
+	assert.False(t, s.Matches("node1", []string{"testnet"}, "blocks"), "wrong match of tags")
+	assert.False(t, s.Matches("node1", []string{"mainnet"}, "lag"), "wrong match of condition")
+	assert.True(t, Silence{}.Matches("node1", nil, "lag"), "wrong match of empty selectors")
+}
+
+func TestNewWindowWhenInvalid(t *testing.T) {
+	valid := configuration.SilenceConfig{
+		Name:           "upgrade",
+		Weekday:        "Tuesday",
+		Start:          "02:00",
+		DurationMinute: 120,
+	}
+	_, err := NewWindow(valid)
+	assert.Nil(t, err, "wrong error")
+
+	config := valid
+	config.Name = ""
+	_, err = NewWindow(config)
+	assert.Equal(t, fault.InvalidSilenceName, err, "wrong name error")
+
+	config = valid
+	config.Weekday = "someday"
+	_, err = NewWindow(config)
+	assert.Equal(t, fault.InvalidSilenceWeekday, err, "wrong weekday error")
+
+	config = valid
+	config.Start = "2am"
+	_, err = NewWindow(config)
+	assert.Equal(t, fault.InvalidSilenceStart, err, "wrong start error")
+
+	config = valid
+	config.DurationMinute = 0
+	_, err = NewWindow(config)
+	assert.Equal(t, fault.InvalidSilenceDuration, err, "wrong duration error")
+
+	config = valid
+	config.Timezone = "Nowhere/Town"
+	_, err = NewWindow(config)
+	assert.NotNil(t, err, "wrong timezone error")
+}
+
+func TestWindowAt(t *testing.T) {
+	w, _ := NewWindow(configuration.SilenceConfig{
+		Name:           "upgrade",
+		Weekday:        "sunday",
+		Start:          "23:00",
+		DurationMinute: 180,
+	})
+
+	// monday 01:00, window opened on sunday is still active
+	now := time.Date(2019, time.December, 2, 1, 0, 0, 0, time.UTC)
+	s := w.At(now)
+	assert.Equal(t, time.Date(2019, time.December, 1, 23, 0, 0, 0, time.UTC), s.Start, "wrong start")
+	assert.Equal(t, time.Date(2019, time.December, 2, 2, 0, 0, 0, time.UTC), s.End, "wrong end")
+	assert.True(t, s.active(now), "wrong active")
+
+	// sunday 22:00, latest window is a week ago
+	now = time.Date(2019, time.December, 8, 22, 0, 0, 0, time.UTC)
+	s = w.At(now)
+	assert.Equal(t, time.Date(2019, time.December, 1, 23, 0, 0, 0, time.UTC), s.Start, "wrong start of last week")
+	assert.False(t, s.active(now), "wrong active")
+}
@@ -0,0 +1,159 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/configuration"
+	"github.com/nlopes/slack"
+)
+
+const (
+	help = "commands: status, status <node>, forks, heights, " +
+		"silence <node | tag:<tag> | alert:<alert>> <duration, e.g. 1h>, unsilence <silence>, " +
+		"ack <node> <alert>"
+)
+
+// Querier - monitor state answering bot commands
+type Querier interface {
+	Acknowledge(string, string) (string, error)
+	Fleet() string
+	Forks() string
+	Heights() string
+	Node(string) (string, error)
+	Silence(string, time.Duration) (string, error)
+	Unsilence(string) (string, error)
+}
+
+// Bot - slack bot answering commands in channel
+type Bot interface {
+	Loop([]interface{})
+}
+
+type bot struct {
+	channelID string
+	client    *slack.Client
+	querier   Querier
+	userID    string
+}
+
+// New - new slack bot listening messages of channel through RTM
+func New(config configuration.SlackConfig, querier Querier) Bot {
+	return &bot{
+		channelID: config.ChannelID,
+		client:    slack.New(config.Token, slack.OptionAPIURL(config.URL)),
+		querier:   querier,
+	}
+}
+
+// Loop - answer commands until shutdown
+func (b *bot) Loop(args []interface{}) {
+	if 1 != len(args) {
+		fmt.Println("bot Loop wrong argument length")
+		return
+	}
+	shutdown := args[0].(<-chan struct{})
+
+	rtm := b.client.NewRTM()
+	go rtm.ManageConnection()
+
+	for {
+		select {
+		case <-shutdown:
+			_ = rtm.Disconnect()
+			fmt.Println("terminate bot loop")
+			return
+
+		case event := <-rtm.IncomingEvents:
+			switch data := event.Data.(type) {
+			case *slack.ConnectedEvent:
+				if nil != data.Info && nil != data.Info.User {
+					b.userID = data.Info.User.ID
+				}
+
+			case *slack.MessageEvent:
+				if data.Channel != b.channelID || "" != data.BotID || "" != data.SubType || b.userID == data.User {
+					continue
+				}
+
+				if reply, ok := b.answer(data.Text); ok {
+					rtm.SendMessage(rtm.NewOutgoingMessage(reply, b.channelID))
+				}
+
+			case *slack.InvalidAuthEvent:
+				fmt.Println("bot invalid auth, terminate bot loop")
+				return
+			}
+		}
+	}
+}
+
+// answer - reply of command, message which is not a command is ignored
+// unless bot is mentioned
+func (b *bot) answer(text string) (string, bool) {
+	mention := fmt.Sprintf("<@%s>", b.userID)
+	mentioned := "" != b.userID && strings.HasPrefix(text, mention)
+	if mentioned {
+		text = strings.TrimPrefix(text, mention)
+	}
+
+	fields := strings.Fields(text)
+	if 0 == len(fields) {
+		return help, mentioned
+	}
+
+	switch strings.ToLower(fields[0]) {
+	case "status":
+		if 1 == len(fields) {
+			return quote(b.querier.Fleet()), true
+		}
+		return reply(b.querier.Node(fields[1]))
+
+	case "forks":
+		return quote(b.querier.Forks()), true
+
+	case "heights":
+		return quote(b.querier.Heights()), true
+
+	case "silence":
+		if 3 != len(fields) {
+			return help, true
+		}
+
+		d, err := time.ParseDuration(fields[2])
+		if nil != err || 0 >= d {
+			return fmt.Sprintf("invalid duration %s", fields[2]), true
+		}
+		return reply(b.querier.Silence(fields[1], d))
+
+	case "unsilence":
+		if 2 != len(fields) {
+			return help, true
+		}
+		return reply(b.querier.Unsilence(fields[1]))
+
+	case "ack":
+		if 3 != len(fields) {
+			return help, true
+		}
+		return reply(b.querier.Acknowledge(fields[1], fields[2]))
+
+	case "help":
+		return help, true
+	}
+
+	return help, mentioned
+}
+
+func reply(msg string, err error) (string, bool) {
+	if nil != err {
+		return err.Error(), true
+	}
+	return quote(msg), true
+}
+
+// quote - keep alignment of lines in slack
+func quote(msg string) string {
+	return fmt.Sprintf("```%s```", msg)
+}
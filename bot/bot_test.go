@@ -0,0 +1,122 @@
+package bot
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/fault"
+	"github.com/stretchr/testify/assert"
+)
+
+type testQuerier struct {
+	silenced     map[string]time.Duration
+	acknowledged []string
+}
+
+func (t *testQuerier) Acknowledge(name string, condition string) (string, error) {
+	if "lag" != condition {
+		return "", fault.UnknownIncident
+	}
+	t.acknowledged = append(t.acknowledged, name+"/"+condition)
+	return fmt.Sprintf("incident %s of %s acknowledged", condition, name), nil
+}
+
+func (t *testQuerier) Fleet() string   { return "fleet" }
+func (t *testQuerier) Forks() string   { return "forks" }
+func (t *testQuerier) Heights() string { return "heights" }
+
+func (t *testQuerier) Node(name string) (string, error) {
+	if "a" != name {
+		return "", fault.UnknownNode
+	}
+	return fmt.Sprintf("node %s", name), nil
+}
+
+func (t *testQuerier) Silence(name string, d time.Duration) (string, error) {
+	t.silenced[name] = d
+	return fmt.Sprintf("silence %s", name), nil
+}
+
+func (t *testQuerier) Unsilence(name string) (string, error) {
+	if _, ok := t.silenced[name]; !ok {
+		return "", fault.UnknownSilence
+	}
+	delete(t.silenced, name)
+	return fmt.Sprintf("silence %s lifted", name), nil
+}
+
+func setupBot() (*bot, *testQuerier) {
+	q := &testQuerier{silenced: make(map[string]time.Duration)}
+	return &bot{querier: q, userID: "U1"}, q
+}
+
+func TestAnswer(t *testing.T) {
+	b, _ := setupBot()
+
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		{"status", "```fleet```"},
+		{"Status a", "```node a```"},
+		{"status b", "unknown node"},
+		{"forks", "```forks```"},
+		{"<@U1> heights", "```heights```"},
+		{"silence a", help},
+		{"silence a 1x", "invalid duration 1x"},
+		{"<@U1> what", help},
+		{"<@U1>", help},
+	}
+
+	for _, test := range tests {
+		reply, ok := b.answer(test.text)
+		assert.True(t, ok, "wrong ignored %s", test.text)
+		assert.Equal(t, test.expected, reply, "wrong reply of %s", test.text)
+	}
+}
+
+func TestAnswerWhenNotCommand(t *testing.T) {
+	b, _ := setupBot()
+
+	_, ok := b.answer("good morning")
+	assert.False(t, ok, "wrong answer of chat")
+
+	_, ok = b.answer("")
+	assert.False(t, ok, "wrong answer of empty message")
+}
+
+func TestAnswerSilence(t *testing.T) {
+	b, q := setupBot()
+
+	reply, ok := b.answer("silence a 1h")
+	assert.True(t, ok, "wrong ignored")
+	assert.Equal(t, "```silence a```", reply, "wrong reply")
+	assert.Equal(t, time.Hour, q.silenced["a"], "wrong duration")
+}
+
+func TestAnswerUnsilence(t *testing.T) {
+	b, q := setupBot()
+
+	reply, _ := b.answer("unsilence tag:mainnet")
+	assert.Equal(t, "unknown silence", reply, "wrong reply of unknown silence")
+
+	_, _ = b.answer("silence tag:mainnet 1h")
+	reply, _ = b.answer("unsilence tag:mainnet")
+	assert.Equal(t, "```silence tag:mainnet lifted```", reply, "wrong reply")
+	assert.Equal(t, 0, len(q.silenced), "wrong silence not lifted")
+}
+
+func TestAnswerAcknowledge(t *testing.T) {
+	b, q := setupBot()
+
+	reply, _ := b.answer("ack a")
+	assert.Equal(t, help, reply, "wrong reply without condition")
+
+	reply, _ = b.answer("ack a fork")
+	assert.Equal(t, "unknown incident", reply, "wrong reply of unknown incident")
+
+	reply, _ = b.answer("<@U1> ack a lag")
+	assert.Equal(t, "```incident lag of a acknowledged```", reply, "wrong reply")
+	assert.Equal(t, []string{"a/lag"}, q.acknowledged, "wrong acknowledged")
+}
@@ -0,0 +1,35 @@
+package capture
+
+import (
+	"io"
+	"time"
+)
+
+// Frame - one broadcast message received from a node
+type Frame struct {
+	Name       string
+	ReceivedAt time.Time
+	Data       [][]byte
+}
+
+// Writer - interface for writing frames into capture files
+type Writer interface {
+	io.Closer
+	Write(Frame) error
+}
+
+// Reader - interface for reading frames from capture files in order
+// io.EOF is returned when all frames are read
+type Reader interface {
+	io.Closer
+	Next() (Frame, error)
+}
+
+// every capture file starts with this header, frames follow as
+// uvarint name length, name, varint unix nano, uvarint parts count, and
+// for every part uvarint length followed by content
+const (
+	fileHeader = "bmcap001"
+	fileSuffix = ".cap"
+	timeFormat = "20060102-150405.000000000"
+)
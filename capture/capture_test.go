@@ -0,0 +1,181 @@
+package capture_test
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/capture"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/configuration"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/fault"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testingDirName = "testing"
+)
+
+func setupTestConfig(size int, count int) configuration.CaptureConfig {
+	_ = os.RemoveAll(testingDirName)
+	return configuration.CaptureConfig{
+		Enable:    true,
+		Directory: testingDirName,
+		File:      "test",
+		Size:      size,
+		Count:     count,
+	}
+}
+
+func teardownTestDir() {
+	_ = os.RemoveAll(testingDirName)
+}
+
+func testFrames(count int) []capture.Frame {
+	now := time.Now()
+	frames := make([]capture.Frame, 0, count)
+	for i := 0; i < count; i++ {
+		frames = append(frames, capture.Frame{
+			Name:       "node",
+			ReceivedAt: now.Add(time.Duration(i) * time.Second),
+			Data:       [][]byte{[]byte("bitmark"), []byte("heart"), []byte("beat")},
+		})
+	}
+	return frames
+}
+
+func readAll(t *testing.T, path string) []capture.Frame {
+	r, err := capture.NewReader(path)
+	assert.Nil(t, err, "wrong new reader")
+	defer r.Close()
+
+	frames := make([]capture.Frame, 0)
+	for {
+		f, err := r.Next()
+		if io.EOF == err {
+			return frames
+		}
+		assert.Nil(t, err, "wrong next")
+		frames = append(frames, f)
+	}
+}
+
+func TestNewWriterWhenInvalidConfig(t *testing.T) {
+	_, err := capture.NewWriter(configuration.CaptureConfig{})
+	assert.NotNil(t, err, "wrong error")
+}
+
+func TestWriteAndRead(t *testing.T) {
+	w, err := capture.NewWriter(setupTestConfig(1048576, 10))
+	defer teardownTestDir()
+	assert.Nil(t, err, "wrong new writer")
+
+	frames := testFrames(10)
+	for _, f := range frames {
+		assert.Nil(t, w.Write(f), "wrong write")
+	}
+	assert.Nil(t, w.Close(), "wrong close")
+
+	actual := readAll(t, testingDirName)
+	assert.Equal(t, len(frames), len(actual), "wrong frame count")
+	for i := range frames {
+		assert.Equal(t, frames[i].Name, actual[i].Name, "wrong name")
+		assert.True(t, frames[i].ReceivedAt.Equal(actual[i].ReceivedAt), "wrong received time")
+		assert.Equal(t, frames[i].Data, actual[i].Data, "wrong data")
+	}
+}
+
+func TestWriteWhenClosed(t *testing.T) {
+	w, _ := capture.NewWriter(setupTestConfig(1048576, 10))
+	defer teardownTestDir()
+
+	_ = w.Close()
+	assert.NotNil(t, w.Write(testFrames(1)[0]), "wrong error")
+}
+
+func TestRotateKeepsNewestFiles(t *testing.T) {
+	w, _ := capture.NewWriter(setupTestConfig(50, 3))
+	defer teardownTestDir()
+
+	frames := testFrames(20)
+	for _, f := range frames {
+		_ = w.Write(f)
+	}
+	_ = w.Close()
+
+	files, _ := ioutil.ReadDir(testingDirName)
+	assert.Equal(t, 3, len(files), "wrong file count")
+
+	actual := readAll(t, testingDirName)
+	assert.True(t, len(actual) < len(frames), "wrong frames not removed")
+	last := actual[len(actual)-1]
+	assert.True(t, frames[len(frames)-1].ReceivedAt.Equal(last.ReceivedAt), "wrong newest frame")
+}
+
+func TestReadWhenTruncated(t *testing.T) {
+	w, _ := capture.NewWriter(setupTestConfig(1048576, 10))
+	defer teardownTestDir()
+
+	for _, f := range testFrames(2) {
+		_ = w.Write(f)
+	}
+	_ = w.Close()
+
+	files, _ := ioutil.ReadDir(testingDirName)
+	path := filepath.Join(testingDirName, files[0].Name())
+	info, _ := os.Stat(path)
+	_ = os.Truncate(path, info.Size()-2)
+
+	actual := readAll(t, path)
+	assert.Equal(t, 1, len(actual), "wrong frame count")
+}
+
+func TestReadWhenPartCountTooLarge(t *testing.T) {
+	_ = os.Mkdir(testingDirName, 0700)
+	defer teardownTestDir()
+
+	// name "a", time 0, then a huge part count without parts
+	record := []byte{1, 'a', 0, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f}
+	path := filepath.Join(testingDirName, "corrupt.cap")
+	_ = ioutil.WriteFile(path, append([]byte("bmcap001"), record...), 0600)
+
+	r, err := capture.NewReader(path)
+	assert.Nil(t, err, "wrong new reader")
+	defer r.Close()
+
+	_, err = r.Next()
+	assert.Equal(t, fault.InvalidCaptureFile, err, "wrong error")
+}
+
+func TestWriteFlushedWithoutClose(t *testing.T) {
+	w, _ := capture.NewWriter(setupTestConfig(1048576, 10))
+	defer teardownTestDir()
+	defer w.Close()
+
+	for _, f := range testFrames(2) {
+		_ = w.Write(f)
+	}
+
+	files, _ := ioutil.ReadDir(testingDirName)
+	path := filepath.Join(testingDirName, files[0].Name())
+	assert.Eventually(t, func() bool {
+		info, _ := os.Stat(path)
+		return len("bmcap001") < int(info.Size())
+	}, 3*time.Second, 100*time.Millisecond, "wrong frames not flushed")
+}
+
+func TestNewReaderWhenInvalidFile(t *testing.T) {
+	_ = os.Mkdir(testingDirName, 0700)
+	defer teardownTestDir()
+
+	path := filepath.Join(testingDirName, "invalid.cap")
+	_ = ioutil.WriteFile(path, []byte("invalid"), 0600)
+
+	r, err := capture.NewReader(path)
+	assert.Nil(t, err, "wrong new reader")
+
+	_, err = r.Next()
+	assert.NotNil(t, err, "wrong error")
+}
@@ -0,0 +1,166 @@
+package capture
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"os"
+	"time"
+
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/fault"
+)
+
+const (
+	// same as network maximum packet size
+	maximumPartSize = 5000000
+
+	// broadcast has chain, category and a few data parts
+	maximumPartCount = 100
+)
+
+type reader struct {
+	buffer *bufio.Reader
+	file   *os.File
+	files  []string
+}
+
+// NewReader - read frames from capture file, or from every capture file in
+// directory ordered by creation time
+func NewReader(path string) (Reader, error) {
+	info, err := os.Stat(path)
+	if nil != err {
+		return nil, err
+	}
+
+	files := []string{path}
+	if info.IsDir() {
+		files, err = captureFiles(path, "")
+		if nil != err {
+			return nil, err
+		}
+	}
+
+	if 0 == len(files) {
+		return nil, fault.InvalidCaptureFile
+	}
+
+	return &reader{files: files}, nil
+}
+
+// Next - next frame, io.EOF when all files are read
+func (r *reader) Next() (Frame, error) {
+	for {
+		if nil == r.file {
+			if 0 == len(r.files) {
+				return Frame{}, io.EOF
+			}
+			if err := r.open(); nil != err {
+				return Frame{}, err
+			}
+		}
+
+		// truncated frame is the last one of a file written before crash
+		f, err := decode(r.buffer)
+		if io.EOF == err || io.ErrUnexpectedEOF == err {
+			_ = r.Close()
+			continue
+		}
+		return f, err
+	}
+}
+
+// Close - close current capture file
+func (r *reader) Close() error {
+	if nil == r.file {
+		return nil
+	}
+
+	err := r.file.Close()
+	r.file = nil
+	r.buffer = nil
+	return err
+}
+
+func (r *reader) open() error {
+	file, err := os.Open(r.files[0])
+	if nil != err {
+		return err
+	}
+	r.files = r.files[1:]
+
+	buffer := bufio.NewReader(file)
+	header := make([]byte, len(fileHeader))
+	if _, err = io.ReadFull(buffer, header); nil != err || fileHeader != string(header) {
+		_ = file.Close()
+		return fault.InvalidCaptureFile
+	}
+
+	r.file = file
+	r.buffer = buffer
+	return nil
+}
+
+// decode - io.EOF only when no byte of next frame exists
+func decode(r *bufio.Reader) (Frame, error) {
+	nameLength, err := binary.ReadUvarint(r)
+	if nil != err {
+		return Frame{}, err
+	}
+
+	name, err := readBytes(r, nameLength)
+	if nil != err {
+		return Frame{}, err
+	}
+
+	nano, err := binary.ReadVarint(r)
+	if nil != err {
+		return Frame{}, unexpected(err)
+	}
+
+	count, err := binary.ReadUvarint(r)
+	if nil != err {
+		return Frame{}, unexpected(err)
+	}
+	if maximumPartCount < count {
+		return Frame{}, fault.InvalidCaptureFile
+	}
+
+	data := make([][]byte, 0, count)
+	for i := uint64(0); i < count; i++ {
+		length, err := binary.ReadUvarint(r)
+		if nil != err {
+			return Frame{}, unexpected(err)
+		}
+
+		part, err := readBytes(r, length)
+		if nil != err {
+			return Frame{}, err
+		}
+		data = append(data, part)
+	}
+
+	return Frame{
+		Name:       string(name),
+		ReceivedAt: time.Unix(0, nano),
+		Data:       data,
+	}, nil
+}
+
+func readBytes(r io.Reader, length uint64) ([]byte, error) {
+	if maximumPartSize < length {
+		return nil, fault.InvalidCaptureFile
+	}
+
+	b := make([]byte, length)
+	if _, err := io.ReadFull(r, b); nil != err {
+		return nil, unexpected(err)
+	}
+	return b, nil
+}
+
+func unexpected(err error) error {
+	if io.EOF == err {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,208 @@
+package capture
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/configuration"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/fault"
+)
+
+type writer struct {
+	sync.Mutex
+	buffer     *bufio.Writer
+	count      int
+	directory  string
+	file       *os.File
+	flushTimer *time.Timer
+	prefix     string
+	size       int64
+	written    int64
+}
+
+const (
+	// frames are flushed in batch off the receive path, at most this late
+	flushInterval = time.Second
+)
+
+// NewWriter - create rotating capture file writer
+// files are named by prefix and creation time, when a file exceeds size a
+// new one is opened, only newest count files are kept
+func NewWriter(config configuration.CaptureConfig) (Writer, error) {
+	if "" == config.Directory || "" == config.File || 0 >= config.Size || 0 >= config.Count {
+		return nil, fault.InvalidCaptureConfig
+	}
+
+	if err := os.MkdirAll(config.Directory, 0700); nil != err {
+		return nil, err
+	}
+
+	w := &writer{
+		count:     config.Count,
+		directory: config.Directory,
+		prefix:    config.File,
+		size:      int64(config.Size),
+	}
+
+	if err := w.rotate(); nil != err {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// Write - append frame to current capture file
+func (w *writer) Write(f Frame) error {
+	w.Lock()
+	defer w.Unlock()
+
+	if nil == w.file {
+		return fault.CaptureClosed
+	}
+
+	record := encode(f)
+	if _, err := w.buffer.Write(record); nil != err {
+		return err
+	}
+	w.written += int64(len(record))
+
+	if w.written >= w.size {
+		return w.rotate()
+	}
+
+	if nil == w.flushTimer {
+		w.flushTimer = time.AfterFunc(flushInterval, w.flush)
+	}
+	return nil
+}
+
+// flush - write buffered frames into file
+func (w *writer) flush() {
+	w.Lock()
+	defer w.Unlock()
+
+	w.flushTimer = nil
+	if nil == w.file {
+		return
+	}
+
+	if err := w.buffer.Flush(); nil != err {
+		fmt.Printf("flush capture with error: %s\n", err)
+	}
+}
+
+// Close - flush and close current capture file
+func (w *writer) Close() error {
+	w.Lock()
+	defer w.Unlock()
+
+	return w.closeFile()
+}
+
+func (w *writer) closeFile() error {
+	if nil != w.flushTimer {
+		w.flushTimer.Stop()
+		w.flushTimer = nil
+	}
+
+	if nil == w.file {
+		return nil
+	}
+
+	err := w.buffer.Flush()
+	if closeErr := w.file.Close(); nil == err {
+		err = closeErr
+	}
+	w.file = nil
+	w.buffer = nil
+	return err
+}
+
+func (w *writer) rotate() error {
+	if err := w.closeFile(); nil != err {
+		return err
+	}
+
+	name := fmt.Sprintf("%s.%s%s", w.prefix, time.Now().UTC().Format(timeFormat), fileSuffix)
+	file, err := os.OpenFile(filepath.Join(w.directory, name), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if nil != err {
+		return err
+	}
+
+	w.file = file
+	w.buffer = bufio.NewWriter(file)
+	w.written = int64(len(fileHeader))
+	if _, err = w.buffer.WriteString(fileHeader); nil != err {
+		return err
+	}
+
+	return w.removeOldFiles()
+}
+
+func (w *writer) removeOldFiles() error {
+	files, err := captureFiles(w.directory, w.prefix)
+	if nil != err {
+		return err
+	}
+
+	if len(files) <= w.count {
+		return nil
+	}
+
+	for _, f := range files[:len(files)-w.count] {
+		if err := os.Remove(f); nil != err {
+			return err
+		}
+	}
+	return nil
+}
+
+// captureFiles - capture files of prefix in directory, oldest first
+func captureFiles(directory string, prefix string) ([]string, error) {
+	infos, err := ioutil.ReadDir(directory)
+	if nil != err {
+		return nil, err
+	}
+
+	files := make([]string, 0, len(infos))
+	for _, info := range infos {
+		name := info.Name()
+		if info.IsDir() || !strings.HasSuffix(name, fileSuffix) {
+			continue
+		}
+		if "" != prefix && !strings.HasPrefix(name, prefix+".") {
+			continue
+		}
+		files = append(files, filepath.Join(directory, name))
+	}
+
+	// creation time is part of file name
+	sort.Strings(files)
+	return files, nil
+}
+
+func encode(f Frame) []byte {
+	size := 3*binary.MaxVarintLen64 + len(f.Name)
+	for _, part := range f.Data {
+		size += binary.MaxVarintLen64 + len(part)
+	}
+
+	buf := make([]byte, size)
+	n := binary.PutUvarint(buf, uint64(len(f.Name)))
+	n += copy(buf[n:], f.Name)
+	n += binary.PutVarint(buf[n:], f.ReceivedAt.UnixNano())
+	n += binary.PutUvarint(buf[n:], uint64(len(f.Data)))
+	for _, part := range f.Data {
+		n += binary.PutUvarint(buf[n:], uint64(len(part)))
+		n += copy(buf[n:], part)
+	}
+	return buf[:n]
+}
@@ -0,0 +1,80 @@
+package communication
+
+import (
+	"context"
+
+	"github.com/bitmark-inc/bitmarkd/blockdigest"
+	"github.com/bitmark-inc/bitmarkd/blockrecord"
+	"github.com/bitmark-inc/bitmarkd/merkle"
+	"github.com/bitmark-inc/bitmarkd/transactionrecord"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/fault"
+)
+
+// BlockHeaderResponse - block response
+type BlockHeaderResponse struct {
+	Digest blockdigest.Digest
+	Header *blockrecord.Header
+}
+
+// BlockResponse - full block response, transaction IDs in block order
+type BlockResponse struct {
+	Digest       blockdigest.Digest
+	Header       *blockrecord.Header
+	Transactions []merkle.Digest
+}
+
+// BlockHeader - get block header of height
+func (c *communication) BlockHeader(ctx context.Context, height uint64) (*BlockHeaderResponse, error) {
+	data, err := c.request(ctx, blockPrefix, packHeight(height))
+	if nil != err {
+		return nil, err
+	}
+
+	header, digest, _, err := blockrecord.ExtractHeader(data, uint64(0))
+	if nil != err {
+		return nil, fault.DecodeReplyFailed
+	}
+
+	return &BlockHeaderResponse{
+		Digest: digest,
+		Header: header,
+	}, nil
+}
+
+// Block - get block of height with transactions unpacked
+func (c *communication) Block(ctx context.Context, height uint64) (*BlockResponse, error) {
+	data, err := c.request(ctx, blockPrefix, packHeight(height))
+	if nil != err {
+		return nil, err
+	}
+
+	header, digest, packed, err := blockrecord.ExtractHeader(data, uint64(0))
+	if nil != err {
+		return nil, fault.DecodeReplyFailed
+	}
+
+	ids, err := transactionIDs(packed, c.testnet)
+	if nil != err {
+		return nil, fault.DecodeReplyFailed
+	}
+
+	return &BlockResponse{
+		Digest:       digest,
+		Header:       header,
+		Transactions: ids,
+	}, nil
+}
+
+// transactionIDs - unpack transactions one by one, ID is digest of packed record
+func transactionIDs(packed []byte, testnet bool) ([]merkle.Digest, error) {
+	ids := make([]merkle.Digest, 0)
+	for 0 < len(packed) {
+		_, n, err := transactionrecord.Packed(packed).Unpack(testnet)
+		if nil != err {
+			return nil, err
+		}
+		ids = append(ids, transactionrecord.Packed(packed[:n]).MakeLink())
+		packed = packed[n:]
+	}
+	return ids, nil
+}
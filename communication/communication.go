@@ -1,35 +1,127 @@
 package communication
 
-import "github.com/jamieabc/bitmarkd-broadcast-monitor/network"
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"time"
 
-//Communication - communication between bitmarkd
+	"github.com/bitmark-inc/bitmarkd/chain"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/fault"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/network"
+	zmq "github.com/pebbe/zmq4"
+)
+
+// Communication - typed requests to bitmarkd command port, every request
+// waits reply until context is done
 type Communication interface {
-	Get(...interface{}) (interface{}, error)
+	Block(context.Context, uint64) (*BlockResponse, error)
+	BlockDigest(context.Context, uint64) (*BlockDigestResponse, error)
+	BlockHeader(context.Context, uint64) (*BlockHeaderResponse, error)
+	Height(context.Context) (*HeightResponse, error)
+	Info(context.Context) (*InfoResponse, error)
 }
 
-//ComType - communication type
-type ComType int
+type communication struct {
+	client  network.Client
+	testnet bool
+}
 
 const (
-	// ComInfo - communication for info
-	ComInfo ComType = iota
+	infoPrefix   = "I"
+	heightPrefix = "N"
+	blockPrefix  = "B"
+	digestPrefix = "H"
+	errorPrefix  = "E"
 
-	// ComHeight - communication for height
-	ComHeight
+	// remote error of missing record, e.g. block not found
+	notFoundSuffix = "not found"
 
-	// ComBlockHeader - communication for block
-	ComBlockHeader
+	// interval to check context while waiting reply
+	pollInterval = 100 * time.Millisecond
 )
 
-//New - new communication
-func New(comType ComType, client network.Client) Communication {
-	switch comType {
-	case ComInfo:
-		return newInfo(client)
-	case ComHeight:
-		return newHeight(client)
-	case ComBlockHeader:
-		return newBlock(client)
-	}
-	return nil
+// New - new communication through REQ client connected to node of chain
+func New(client network.Client, blockchain string) Communication {
+	return &communication{
+		client:  client,
+		testnet: chain.Bitmark != blockchain,
+	}
+}
+
+// request - send command and wait reply of it, reply is data after prefix
+//
+// REQ socket is relaxed and correlated, next request is still allowed when
+// reply of previous one is abandoned
+func (c *communication) request(ctx context.Context, prefix string, params ...interface{}) ([]byte, error) {
+	if err := ctx.Err(); nil != err {
+		return nil, contextError(err)
+	}
+
+	items := append([]interface{}{prefix}, params...)
+	if err := c.client.Send(items...); nil != err {
+		return nil, err
+	}
+
+	poller := zmq.NewPoller()
+	poller.Add(c.client.Socket(), zmq.POLLIN)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, contextError(ctx.Err())
+		default:
+		}
+
+		polled, err := poller.Poll(pollInterval)
+		if nil != err {
+			return nil, err
+		}
+		if 0 == len(polled) {
+			continue
+		}
+
+		data, err := c.client.Receive(0)
+		if nil != err {
+			return nil, err
+		}
+		return reply(prefix, data)
+	}
+}
+
+func reply(prefix string, data [][]byte) ([]byte, error) {
+	if 0 == len(data) {
+		return nil, fault.ShortReply
+	}
+
+	if errorPrefix == string(data[0]) && 2 == len(data) {
+		if strings.HasSuffix(string(data[1]), notFoundSuffix) {
+			return nil, fault.RecordNotFound
+		}
+		return nil, fmt.Errorf("remote error: %s", data[1])
+	}
+
+	if prefix != string(data[0]) {
+		return nil, fault.WrongReplyPrefix
+	}
+
+	if 2 != len(data) {
+		return nil, fault.ShortReply
+	}
+
+	return data[1], nil
+}
+
+func contextError(err error) error {
+	if context.DeadlineExceeded == err {
+		return fault.RequestTimeout
+	}
+	return err
+}
+
+func packHeight(height uint64) []byte {
+	params := make([]byte, 8)
+	binary.BigEndian.PutUint64(params, height)
+	return params
 }
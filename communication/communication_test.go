@@ -0,0 +1,172 @@
+package communication_test
+
+import (
+	"context"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/bitmark-inc/bitmarkd/transactionrecord"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/communication"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/fakebitmarkd"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/fault"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/network"
+	zmq "github.com/pebbe/zmq4"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testChain   = "testing"
+	testTimeout = 5 * time.Second
+)
+
+func setupServerAndClient(t *testing.T) (*fakebitmarkd.Server, network.Client) {
+	server, err := fakebitmarkd.NewServer(testChain)
+	if nil != err {
+		t.Fatalf("new server with error: %s", err)
+	}
+
+	public, private, _ := fakebitmarkd.NewKeys()
+	publicKey, _ := network.ReadPublicKey(public)
+	privateKey, _ := network.ReadPrivateKey(private)
+	serverKey, _ := hex.DecodeString(server.PublicKey())
+
+	client, err := network.NewClient(zmq.REQ, privateKey, publicKey, testTimeout)
+	if nil != err {
+		t.Fatalf("new client with error: %s", err)
+	}
+
+	conn, _ := network.NewConnection("127.0.0.1:" + server.CommandPort())
+	if err = client.Connect(conn, serverKey, testChain); nil != err {
+		t.Fatalf("connect with error: %s", err)
+	}
+
+	return server, client
+}
+
+func TestInfo(t *testing.T) {
+	server, client := setupServerAndClient(t)
+	defer server.Close()
+	defer client.Close()
+
+	server.SetInfo("1.2.3", false)
+	_, _ = server.AddBlock(uint64(100), time.Now())
+
+	info, err := communication.New(client, testChain).Info(context.Background())
+	assert.Nil(t, err, "wrong error")
+	assert.Equal(t, "1.2.3", info.Version, "wrong version")
+	assert.Equal(t, testChain, info.Chain, "wrong chain")
+	assert.Equal(t, false, info.Normal, "wrong normal")
+	assert.Equal(t, uint64(100), info.Height, "wrong height")
+}
+
+func TestHeight(t *testing.T) {
+	server, client := setupServerAndClient(t)
+	defer server.Close()
+	defer client.Close()
+
+	_, _ = server.AddBlock(uint64(123), time.Now())
+
+	reply, err := communication.New(client, testChain).Height(context.Background())
+	assert.Nil(t, err, "wrong error")
+	assert.Equal(t, uint64(123), reply.Height, "wrong height")
+}
+
+func TestBlockHeader(t *testing.T) {
+	server, client := setupServerAndClient(t)
+	defer server.Close()
+	defer client.Close()
+
+	now := time.Now()
+	_, _ = server.AddBlock(uint64(10), now)
+
+	reply, err := communication.New(client, testChain).BlockHeader(context.Background(), uint64(10))
+	assert.Nil(t, err, "wrong error")
+	assert.Equal(t, uint64(10), reply.Header.Number, "wrong block number")
+	assert.Equal(t, uint64(now.Unix()), reply.Header.Timestamp, "wrong timestamp")
+}
+
+func TestBlockHeaderWhenNotFound(t *testing.T) {
+	server, client := setupServerAndClient(t)
+	defer server.Close()
+	defer client.Close()
+
+	_, err := communication.New(client, testChain).BlockHeader(context.Background(), uint64(10))
+	assert.Equal(t, fault.RecordNotFound, err, "wrong error")
+}
+
+func TestBlockDigest(t *testing.T) {
+	server, client := setupServerAndClient(t)
+	defer server.Close()
+	defer client.Close()
+
+	_, _ = server.AddBlock(uint64(10), time.Now())
+	_, _ = server.AddBlock(uint64(11), time.Now())
+
+	comm := communication.New(client, testChain)
+	reply, err := comm.BlockDigest(context.Background(), uint64(11))
+	assert.Nil(t, err, "wrong error")
+
+	header, err := comm.BlockHeader(context.Background(), uint64(11))
+	assert.Nil(t, err, "wrong error")
+	assert.Equal(t, header.Digest, reply.Digest, "wrong digest")
+}
+
+func TestBlock(t *testing.T) {
+	server, client := setupServerAndClient(t)
+	defer server.Close()
+	defer client.Close()
+
+	issue1, err := fakebitmarkd.NewIssue(testChain)
+	assert.Nil(t, err, "wrong issue error")
+	issue2, err := fakebitmarkd.NewIssue(testChain)
+	assert.Nil(t, err, "wrong issue error")
+
+	_, _ = server.AddBlock(uint64(10), time.Now(), issue1, issue2)
+
+	block, err := communication.New(client, testChain).Block(context.Background(), uint64(10))
+	assert.Nil(t, err, "wrong error")
+	assert.Equal(t, uint64(10), block.Header.Number, "wrong block number")
+	assert.Equal(t, 2, len(block.Transactions), "wrong transaction count")
+	assert.Equal(t, transactionrecord.Packed(issue1).MakeLink(), block.Transactions[0], "wrong transaction ID")
+	assert.Equal(t, transactionrecord.Packed(issue2).MakeLink(), block.Transactions[1], "wrong transaction ID")
+}
+
+func TestRequestWhenTimeout(t *testing.T) {
+	server, client := setupServerAndClient(t)
+	defer server.Close()
+	defer client.Close()
+
+	server.SetReplyDelay(time.Second)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	comm := communication.New(client, testChain)
+	_, err := comm.Height(ctx)
+	assert.Equal(t, fault.RequestTimeout, err, "wrong error")
+
+	// abandoned reply does not block next request
+	server.SetReplyDelay(0)
+	_, err = comm.Height(context.Background())
+	assert.Nil(t, err, "wrong error after timeout")
+}
+
+func TestRequestWhenCancelled(t *testing.T) {
+	server, client := setupServerAndClient(t)
+	defer server.Close()
+	defer client.Close()
+
+	server.SetReplyDelay(time.Second)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-time.After(100 * time.Millisecond)
+		cancel()
+	}()
+
+	start := time.Now()
+	_, err := communication.New(client, testChain).Info(ctx)
+	assert.Equal(t, context.Canceled, err, "wrong error")
+	assert.True(t, time.Since(start) < time.Second, "wrong cancel wait")
+}
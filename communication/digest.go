@@ -0,0 +1,28 @@
+package communication
+
+import (
+	"context"
+
+	"github.com/bitmark-inc/bitmarkd/blockdigest"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/fault"
+)
+
+// BlockDigestResponse - block digest response
+type BlockDigestResponse struct {
+	Digest blockdigest.Digest
+}
+
+// BlockDigest - get block digest of height
+func (c *communication) BlockDigest(ctx context.Context, height uint64) (*BlockDigestResponse, error) {
+	data, err := c.request(ctx, digestPrefix, packHeight(height))
+	if nil != err {
+		return nil, err
+	}
+
+	var result blockdigest.Digest
+	if err = blockdigest.DigestFromBytes(&result, data); nil != err {
+		return nil, fault.DecodeReplyFailed
+	}
+
+	return &BlockDigestResponse{Digest: result}, nil
+}
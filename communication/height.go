@@ -1,44 +1,27 @@
 package communication
 
 import (
+	"context"
 	"encoding/binary"
-	"fmt"
 
-	"github.com/jamieabc/bitmarkd-broadcast-monitor/network"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/fault"
 )
 
-type height struct {
-	client network.Client
-	prefix string
-}
-
 //HeightResponse - json response of height command
 type HeightResponse struct {
 	Height uint64
 }
 
-//Get - get height
-func (h *height) Get(payload ...interface{}) (interface{}, error) {
-	err := h.client.Send(h.prefix)
+// Height - get height
+func (c *communication) Height(ctx context.Context) (*HeightResponse, error) {
+	data, err := c.request(ctx, heightPrefix)
 	if nil != err {
 		return nil, err
 	}
 
-	data, err := h.client.Receive(0)
-	if nil != err {
-		return nil, err
+	if 8 > len(data) {
+		return nil, fault.ShortReply
 	}
 
-	if h.prefix != string(data[0]) {
-		return nil, fmt.Errorf("wrong command")
-	}
-
-	return &HeightResponse{Height: binary.BigEndian.Uint64(data[1])}, nil
-}
-
-func newHeight(client network.Client) Communication {
-	return &height{
-		client: client,
-		prefix: "N",
-	}
+	return &HeightResponse{Height: binary.BigEndian.Uint64(data)}, nil
 }
@@ -1,17 +1,13 @@
 package communication
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 
-	"github.com/jamieabc/bitmarkd-broadcast-monitor/network"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/fault"
 )
 
-type info struct {
-	client network.Client
-	prefix string
-}
-
 //InfoResponse - info response
 type InfoResponse struct {
 	Version string `json:"version"`
@@ -24,33 +20,16 @@ func (i *InfoResponse) String() string {
 	return fmt.Sprintf("version %s, chain %s, height %d, normal %t", i.Version, i.Chain, i.Height, i.Normal)
 }
 
-func newInfo(client network.Client) Communication {
-	return &info{
-		client: client,
-		prefix: "I",
-	}
-}
-
-//Get - get info
-func (i *info) Get(payload ...interface{}) (interface{}, error) {
-	err := i.client.Send(i.prefix)
+// Info - get info
+func (c *communication) Info(ctx context.Context) (*InfoResponse, error) {
+	data, err := c.request(ctx, infoPrefix)
 	if nil != err {
 		return nil, err
 	}
 
-	data, err := i.client.Receive(0)
-	if nil != err {
-		return nil, err
-	}
-
-	if i.prefix != string(data[0]) {
-		return nil, fmt.Errorf("wrong command")
-	}
-
 	var info InfoResponse
-	err = json.Unmarshal(data[1], &info)
-	if nil != err {
-		return nil, err
+	if err = json.Unmarshal(data, &info); nil != err {
+		return nil, fault.DecodeReplyFailed
 	}
 
 	return &info, nil
@@ -6,17 +6,34 @@ import (
 	"strings"
 
 	"github.com/bitmark-inc/logger"
+	"github.com/yuin/gluamapper"
+	lua "github.com/yuin/gopher-lua"
 )
 
 // Configuration - configuration interface
 type Configuration interface {
+	AlertConfig() AlertConfig
+	DigestConfig() DigestConfig
+	CaptureConfig() CaptureConfig
 	Data() *configuration
+	EmailConfig() EmailConfig
 	HeartbeatIntervalInSecond() int
+	HeartbeatSilenceInBeats() int
+	HeightConfig() HeightConfig
+	ConfirmTimeoutInMinute() int
 	Influx() InfluxDBConfig
 	Key() Keys
 	LogConfig() logger.Configuration
+	MessengersConfig() []MessengerConfig
 	NodesConfig() []NodeConfig
+	PagerDutyConfig() PagerDutyConfig
+	RoutesConfig() []RouteConfig
+	RulesConfig() []RuleConfig
+	SilencesConfig() []SilenceConfig
+	TelegramConfig() TelegramConfig
 	SlackConfig() SlackConfig
+	TemplatesConfig() map[string]string
+	WebhookConfig() WebhookConfig
 	String() string
 }
 
@@ -25,18 +42,35 @@ type configuration struct {
 	Keys                    Keys                 `gluamapper:"keys"`
 	Logging                 logger.Configuration `gluamapper:"logging"`
 	HeartbeatIntervalSecond int                  `gluamapper:"heartbeat_interval_second"`
+	HeartbeatSilenceBeats   int                  `gluamapper:"heartbeat_silence_beats"`
 	InfluxDB                InfluxDBConfig       `gluamapper:"influxdb"`
 	Slack                   SlackConfig          `gluamapper:"slack"`
+	Capture                 CaptureConfig        `gluamapper:"capture"`
+	ConfirmTimeoutMinute    int                  `gluamapper:"transaction_confirm_timeout_minute"`
+	Height                  HeightConfig         `gluamapper:"height"`
+	Alert                   AlertConfig          `gluamapper:"alert"`
+	Digest                  DigestConfig         `gluamapper:"digest"`
+	Webhook                 WebhookConfig        `gluamapper:"webhook"`
+	Email                   EmailConfig          `gluamapper:"email"`
+	PagerDuty               PagerDutyConfig      `gluamapper:"pagerduty"`
+	Telegram                TelegramConfig       `gluamapper:"telegram"`
+	Rules                   []RuleConfig         `gluamapper:"alerts"`
+	Silences                []SilenceConfig      `gluamapper:"silences"`
+	Messengers              []MessengerConfig    `gluamapper:"messengers"`
+	Routes                  []RouteConfig        `gluamapper:"routes"`
+	Templates               map[string]string    `gluamapper:"templates"`
 }
 
 // NodeConfig - node config
 type NodeConfig struct {
-	IP            string `gluamapper:"ip"`
-	BroadcastPort string `gluamapper:"broadcast_port"`
-	CommandPort   string `gluamapper:"command_port"`
-	Chain         string `gluamapper:"chain"`
-	Name          string `gluamapper:"name"`
-	PublicKey     string `gluamapper:"public_key"`
+	IP            string   `gluamapper:"ip"`
+	BroadcastPort string   `gluamapper:"broadcast_port"`
+	CommandPort   string   `gluamapper:"command_port"`
+	Chain         string   `gluamapper:"chain"`
+	Name          string   `gluamapper:"name"`
+	PublicKey     string   `gluamapper:"public_key"`
+	Subscribe     []string `gluamapper:"subscribe"`
+	Tags          []string `gluamapper:"tags"`
 }
 
 // InfluxDBConfig - influxdb config
@@ -48,10 +82,151 @@ type InfluxDBConfig struct {
 	Password string `gluamapper:"password"`
 }
 
-// SlackConfig - slack config
+// SlackConfig - slack web api config, url is api base url ending with slash,
+// bot answers commands in channel
 type SlackConfig struct {
+	URL       string `gluamapper:"url"`
 	Token     string `gluamapper:"token"`
 	ChannelID string `gluamapper:"channel_id"`
+	Retry     int    `gluamapper:"retry"`
+	Bot       bool   `gluamapper:"bot"`
+}
+
+// WebhookConfig - webhook config, body is rendered from go template
+type WebhookConfig struct {
+	URL                string            `gluamapper:"url"`
+	Template           string            `gluamapper:"template"`
+	Headers            map[string]string `gluamapper:"headers"`
+	Secret             string            `gluamapper:"secret"`
+	SignatureHeader    string            `gluamapper:"signature_header"`
+	Retry              int               `gluamapper:"retry"`
+	BackoffMillisecond int               `gluamapper:"backoff_millisecond"`
+	TimeoutSecond      int               `gluamapper:"timeout_second"`
+}
+
+// EmailConfig - smtp email config
+type EmailConfig struct {
+	Host          string   `gluamapper:"host"`
+	Port          string   `gluamapper:"port"`
+	Username      string   `gluamapper:"username"`
+	Password      string   `gluamapper:"password"`
+	StartTLS      bool     `gluamapper:"starttls"`
+	From          string   `gluamapper:"from"`
+	To            []string `gluamapper:"to"`
+	Subject       string   `gluamapper:"subject"`
+	TimeoutSecond int      `gluamapper:"timeout_second"`
+}
+
+// PagerDutyConfig - pagerduty events v2 config, empty conditions pages every
+// condition
+type PagerDutyConfig struct {
+	URL                string   `gluamapper:"url"`
+	RoutingKey         string   `gluamapper:"routing_key"`
+	Source             string   `gluamapper:"source"`
+	Severity           string   `gluamapper:"severity"`
+	Conditions         []string `gluamapper:"conditions"`
+	Retry              int      `gluamapper:"retry"`
+	BackoffMillisecond int      `gluamapper:"backoff_millisecond"`
+	TimeoutSecond      int      `gluamapper:"timeout_second"`
+}
+
+// TelegramConfig - telegram bot api config, chats are chat IDs of each
+// severity
+type TelegramConfig struct {
+	URL                string              `gluamapper:"url"`
+	Token              string              `gluamapper:"token"`
+	Chats              map[string][]string `gluamapper:"chats"`
+	Retry              int                 `gluamapper:"retry"`
+	BackoffMillisecond int                 `gluamapper:"backoff_millisecond"`
+	TimeoutSecond      int                 `gluamapper:"timeout_second"`
+}
+
+// CaptureConfig - raw broadcast capture config
+type CaptureConfig struct {
+	Enable    bool   `gluamapper:"enable"`
+	Directory string `gluamapper:"directory"`
+	File      string `gluamapper:"file"`
+	Size      int    `gluamapper:"size"`
+	Count     int    `gluamapper:"count"`
+}
+
+// HeightConfig - height polling and lag alert config
+type HeightConfig struct {
+	IntervalSecond int `gluamapper:"interval_second"`
+	LagThreshold   int `gluamapper:"lag_threshold"`
+	LagMinute      int `gluamapper:"lag_minute"`
+}
+
+// AlertConfig - alert deduplication and grouping config
+type AlertConfig struct {
+	CooldownMinute int `gluamapper:"cooldown_minute"`
+	GroupSecond    int `gluamapper:"group_second"`
+}
+
+// DigestConfig - periodic report of nodes, daily report is sent at hour,
+// weekly report is sent at hour of weekday, reports are saved in directory
+// and sent to messengers, empty messengers sends to all
+type DigestConfig struct {
+	Daily      bool     `gluamapper:"daily"`
+	Weekly     bool     `gluamapper:"weekly"`
+	Hour       int      `gluamapper:"hour"`
+	Weekday    string   `gluamapper:"weekday"`
+	Timezone   string   `gluamapper:"timezone"`
+	Directory  string   `gluamapper:"directory"`
+	Messengers []string `gluamapper:"messengers"`
+}
+
+// MessengerConfig - named messenger instance, type is one of slack, webhook,
+// email, telegram and pagerduty, only config of type is used
+type MessengerConfig struct {
+	Name      string          `gluamapper:"name"`
+	Type      string          `gluamapper:"type"`
+	Slack     SlackConfig     `gluamapper:"slack"`
+	Webhook   WebhookConfig   `gluamapper:"webhook"`
+	Email     EmailConfig     `gluamapper:"email"`
+	Telegram  TelegramConfig  `gluamapper:"telegram"`
+	PagerDuty PagerDutyConfig `gluamapper:"pagerduty"`
+}
+
+// RouteConfig - alerts of selected severities, nodes, chains and tags are
+// sent to messengers, empty selector selects all, first matching route is
+// taken unless it continues to following routes
+type RouteConfig struct {
+	Messengers []string `gluamapper:"messengers"`
+	Severities []string `gluamapper:"severities"`
+	Nodes      []string `gluamapper:"nodes"`
+	Chains     []string `gluamapper:"chains"`
+	Tags       []string `gluamapper:"tags"`
+	Continue   bool     `gluamapper:"continue"`
+}
+
+// RuleConfig - alert when metric of selected nodes compares true with
+// threshold for duration, empty nodes selects all nodes, empty messengers
+// sends to all messengers
+type RuleConfig struct {
+	Name           string   `gluamapper:"name"`
+	Metric         string   `gluamapper:"metric"`
+	Comparison     string   `gluamapper:"comparison"`
+	Threshold      float64  `gluamapper:"threshold"`
+	DurationMinute int      `gluamapper:"duration_minute"`
+	Hysteresis     float64  `gluamapper:"hysteresis"`
+	Severity       string   `gluamapper:"severity"`
+	Nodes          []string `gluamapper:"nodes"`
+	Messengers     []string `gluamapper:"messengers"`
+}
+
+// SilenceConfig - weekly maintenance window, alerts of selected nodes, tags
+// and conditions are suppressed from start for duration, empty selector
+// selects all, weekday is english name of day, start is hh:mm in timezone
+type SilenceConfig struct {
+	Name           string   `gluamapper:"name"`
+	Weekday        string   `gluamapper:"weekday"`
+	Start          string   `gluamapper:"start"`
+	DurationMinute int      `gluamapper:"duration_minute"`
+	Timezone       string   `gluamapper:"timezone"`
+	Nodes          []string `gluamapper:"nodes"`
+	Tags           []string `gluamapper:"tags"`
+	Conditions     []string `gluamapper:"conditions"`
 }
 
 // Keys - public and private keys
@@ -62,6 +237,8 @@ type Keys struct {
 
 const (
 	defaultHeartbeatIntervalSecond = 60
+	defaultHeartbeatSilenceBeats   = 3
+	defaultConfirmTimeoutMinute    = 60
 )
 
 var (
@@ -75,6 +252,106 @@ var (
 		},
 		Size: 1048576,
 	}
+
+	defaultCapture = CaptureConfig{
+		Enable:    false,
+		Directory: "capture",
+		File:      "broadcast",
+		Size:      10485760,
+		Count:     100,
+	}
+
+	defaultHeight = HeightConfig{
+		IntervalSecond: 60,
+		LagThreshold:   3,
+		LagMinute:      5,
+	}
+
+	defaultSlack = SlackConfig{
+		URL:   "https://slack.com/api/",
+		Retry: 3,
+	}
+
+	defaultWebhook = WebhookConfig{
+		Template:           `{"text": {{ json .Message }}}`,
+		Headers:            map[string]string{"Content-Type": "application/json"},
+		SignatureHeader:    "X-Signature",
+		Retry:              3,
+		BackoffMillisecond: 1000,
+		TimeoutSecond:      10,
+	}
+
+	defaultEmail = EmailConfig{
+		Port:          "587",
+		StartTLS:      true,
+		Subject:       "bitmarkd broadcast monitor alert",
+		TimeoutSecond: 10,
+	}
+
+	defaultPagerDuty = PagerDutyConfig{
+		URL:                "https://events.pagerduty.com/v2/enqueue",
+		Source:             "bitmarkd-broadcast-monitor",
+		Severity:           "critical",
+		Retry:              3,
+		BackoffMillisecond: 1000,
+		TimeoutSecond:      10,
+	}
+
+	defaultTelegram = TelegramConfig{
+		URL:                "https://api.telegram.org",
+		Retry:              3,
+		BackoffMillisecond: 1000,
+		TimeoutSecond:      10,
+	}
+
+	// same threshold as transaction summary, rate of two summaries in a row
+	// above threshold fires, and clears below 8%, any missing block, fork or
+	// block confirmed in 30 minutes or longer fires at once
+	defaultRules = []RuleConfig{
+		{
+			Name:           "drop-rate",
+			Metric:         "drop-rate",
+			Comparison:     ">",
+			Threshold:      0.1,
+			DurationMinute: 2,
+			Hysteresis:     0.02,
+			Severity:       "warning",
+		},
+		{
+			Name:       "missing-blocks",
+			Metric:     "missing-blocks",
+			Comparison: ">",
+			Threshold:  0,
+			Severity:   "warning",
+		},
+		{
+			Name:       "fork-depth",
+			Metric:     "fork-depth",
+			Comparison: ">",
+			Threshold:  0,
+			Severity:   "warning",
+		},
+		{
+			Name:       "long-confirm",
+			Metric:     "confirm-time",
+			Comparison: ">=",
+			Threshold:  30,
+			Severity:   "warning",
+		},
+	}
+
+	defaultAlert = AlertConfig{
+		CooldownMinute: 30,
+		GroupSecond:    10,
+	}
+
+	defaultDigest = DigestConfig{
+		Daily:     false,
+		Weekly:    false,
+		Hour:      9,
+		Weekday:   "monday",
+		Directory: "digest",
+	}
 )
 
 // Parse - parse configuration
@@ -88,9 +365,20 @@ func Parse(configFile string) (Configuration, error) {
 	config := &configuration{
 		Logging:                 defaultLogging,
 		HeartbeatIntervalSecond: defaultHeartbeatIntervalSecond,
+		HeartbeatSilenceBeats:   defaultHeartbeatSilenceBeats,
+		ConfirmTimeoutMinute:    defaultConfirmTimeoutMinute,
+		Capture:                 defaultCapture,
+		Height:                  defaultHeight,
+		Alert:                   defaultAlert,
+		Digest:                  defaultDigest,
+		Slack:                   defaultSlack,
+		Webhook:                 defaultWebhook,
+		Email:                   defaultEmail,
+		PagerDuty:               defaultPagerDuty,
+		Telegram:                defaultTelegram,
 	}
 
-	if err := parseLuaConfigurationFile(filePath, config); nil != err {
+	if err := parseLuaConfigurationFile(filePath, config, config.mapMessengers); nil != err {
 		fmt.Printf("parse lua config with error: %s", err)
 		return nil, err
 	}
@@ -120,7 +408,7 @@ func (c *configuration) String() string {
 	str.WriteString("nodes:\n")
 	for i, node := range c.Nodes {
 		str.WriteString(fmt.Sprintf(
-			"\tnode[%d]:\n\t\taddress: \t%s\n\t\tbroadcast port: %s\n\t\tcommand port: \t%s\n\t\tpublic key: \t%s\n\t\tchain: %s\n\t\tname: \t%s\n",
+			"\tnode[%d]:\n\t\taddress: \t%s\n\t\tbroadcast port: %s\n\t\tcommand port: \t%s\n\t\tpublic key: \t%s\n\t\tchain: %s\n\t\tname: \t%s\n\t\tsubscribe: \t%v\n\t\ttags: \t%v\n",
 			i,
 			node.IP,
 			node.BroadcastPort,
@@ -128,9 +416,13 @@ func (c *configuration) String() string {
 			node.PublicKey,
 			node.Chain,
 			node.Name,
+			node.Subscribe,
+			node.Tags,
 		))
 	}
 	str.WriteString(fmt.Sprintf("heartbeat interval: %d seconds\n", c.HeartbeatIntervalSecond))
+	str.WriteString(fmt.Sprintf("heartbeat silence: %d beats\n", c.HeartbeatSilenceBeats))
+	str.WriteString(fmt.Sprintf("transaction confirm timeout: %d minutes\n", c.ConfirmTimeoutMinute))
 	str.WriteString(fmt.Sprintf("logging: %+v\n", c.Logging))
 	str.WriteString("influx database:\n")
 	str.WriteString(fmt.Sprintf("\tip:\t%s\n\tport:\t%s\n\tuser:\t%s\n\tpassword:\t%s\n",
@@ -139,8 +431,104 @@ func (c *configuration) String() string {
 		c.InfluxDB.User,
 		c.InfluxDB.Password))
 	str.WriteString(fmt.Sprintf(
-		"\tslack:\n\t\ttoken:%s\n\t\tchannel ID: %s\n",
-		c.Slack.Token, c.Slack.ChannelID))
+		"\tslack:\n\t\turl: %s\n\t\ttoken:%s\n\t\tchannel ID: %s\n\t\tretry: %d\n\t\tbot: %t\n",
+		c.Slack.URL, c.Slack.Token, c.Slack.ChannelID, c.Slack.Retry, c.Slack.Bot))
+	str.WriteString(fmt.Sprintf(
+		"capture:\n\tenable: %t\n\tdirectory: %s\n\tfile: %s\n\tsize: %d\n\tcount: %d\n",
+		c.Capture.Enable,
+		c.Capture.Directory,
+		c.Capture.File,
+		c.Capture.Size,
+		c.Capture.Count))
+	str.WriteString(fmt.Sprintf(
+		"height:\n\tinterval: %d seconds\n\tlag threshold: %d blocks\n\tlag: %d minutes\n",
+		c.Height.IntervalSecond,
+		c.Height.LagThreshold,
+		c.Height.LagMinute))
+	str.WriteString(fmt.Sprintf(
+		"alert:\n\tcooldown: %d minutes\n\tgroup: %d seconds\n",
+		c.Alert.CooldownMinute,
+		c.Alert.GroupSecond))
+	str.WriteString(fmt.Sprintf(
+		"webhook:\n\turl: %s\n\theaders: %v\n\tsignature header: %s\n\tretry: %d\n",
+		c.Webhook.URL,
+		c.Webhook.Headers,
+		c.Webhook.SignatureHeader,
+		c.Webhook.Retry))
+	str.WriteString(fmt.Sprintf(
+		"email:\n\thost: %s\n\tport: %s\n\tusername: %s\n\tstarttls: %t\n\tfrom: %s\n\tto: %v\n",
+		c.Email.Host,
+		c.Email.Port,
+		c.Email.Username,
+		c.Email.StartTLS,
+		c.Email.From,
+		c.Email.To))
+	str.WriteString(fmt.Sprintf(
+		"pagerduty:\n\turl: %s\n\tsource: %s\n\tseverity: %s\n\tconditions: %v\n\tretry: %d\n",
+		c.PagerDuty.URL,
+		c.PagerDuty.Source,
+		c.PagerDuty.Severity,
+		c.PagerDuty.Conditions,
+		c.PagerDuty.Retry))
+	str.WriteString(fmt.Sprintf(
+		"telegram:\n\turl: %s\n\tchats: %v\n\tretry: %d\n",
+		c.Telegram.URL,
+		c.Telegram.Chats,
+		c.Telegram.Retry))
+	str.WriteString("alerts:\n")
+	for _, r := range c.RulesConfig() {
+		str.WriteString(fmt.Sprintf(
+			"\t%s: %s %s %v for %d minutes, hysteresis %v, severity: %s, nodes: %v, messengers: %v\n",
+			r.Name,
+			r.Metric,
+			r.Comparison,
+			r.Threshold,
+			r.DurationMinute,
+			r.Hysteresis,
+			r.Severity,
+			r.Nodes,
+			r.Messengers,
+		))
+	}
+	str.WriteString(fmt.Sprintf(
+		"digest:\n\tdaily: %t\n\tweekly: %t\n\thour: %d\n\tweekday: %s\n\ttimezone: %s\n\tdirectory: %s\n\tmessengers: %v\n",
+		c.Digest.Daily,
+		c.Digest.Weekly,
+		c.Digest.Hour,
+		c.Digest.Weekday,
+		c.Digest.Timezone,
+		c.Digest.Directory,
+		c.Digest.Messengers))
+	str.WriteString("messengers:\n")
+	for _, m := range c.Messengers {
+		str.WriteString(fmt.Sprintf("\t%s: %s\n", m.Name, m.Type))
+	}
+	str.WriteString("routes:\n")
+	for _, r := range c.Routes {
+		str.WriteString(fmt.Sprintf(
+			"\tmessengers: %v, severities: %v, nodes: %v, chains: %v, tags: %v, continue: %t\n",
+			r.Messengers,
+			r.Severities,
+			r.Nodes,
+			r.Chains,
+			r.Tags,
+			r.Continue,
+		))
+	}
+	str.WriteString("silences:\n")
+	for _, w := range c.Silences {
+		str.WriteString(fmt.Sprintf(
+			"\t%s: %s %s %s for %d minutes, nodes: %v, tags: %v, conditions: %v\n",
+			w.Name,
+			w.Weekday,
+			w.Start,
+			w.Timezone,
+			w.DurationMinute,
+			w.Nodes,
+			w.Tags,
+			w.Conditions,
+		))
+	}
 	return str.String()
 }
 
@@ -154,6 +542,132 @@ func (c *configuration) HeartbeatIntervalInSecond() int {
 	return c.HeartbeatIntervalSecond
 }
 
+// HeartbeatSilenceInBeats - node is silent when no heartbeat arrives within
+// this many heartbeat intervals, 0 disables silence detection
+func (c *configuration) HeartbeatSilenceInBeats() int {
+	return c.HeartbeatSilenceBeats
+}
+
+// ConfirmTimeoutInMinute - alert on broadcast transaction not in block after timeout
+func (c *configuration) ConfirmTimeoutInMinute() int {
+	return c.ConfirmTimeoutMinute
+}
+
+// EmailConfig - return email config
+func (c *configuration) EmailConfig() EmailConfig {
+	return c.Email
+}
+
+// PagerDutyConfig - return pagerduty config
+func (c *configuration) PagerDutyConfig() PagerDutyConfig {
+	return c.PagerDuty
+}
+
+// TelegramConfig - return telegram config
+func (c *configuration) TelegramConfig() TelegramConfig {
+	return c.Telegram
+}
+
+// RulesConfig - return alert rules, default rule is added unless configured
+// rule has its metric or name
+func (c *configuration) RulesConfig() []RuleConfig {
+	rules := make([]RuleConfig, 0, len(c.Rules)+len(defaultRules))
+	rules = append(rules, c.Rules...)
+	for _, d := range defaultRules {
+		if !overridden(c.Rules, d) {
+			rules = append(rules, d)
+		}
+	}
+	return rules
+}
+
+func overridden(rules []RuleConfig, d RuleConfig) bool {
+	for _, r := range rules {
+		if r.Metric == d.Metric || r.Name == d.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// SilencesConfig - return weekly maintenance windows
+func (c *configuration) SilencesConfig() []SilenceConfig {
+	return c.Silences
+}
+
+// WebhookConfig - return webhook config
+func (c *configuration) WebhookConfig() WebhookConfig {
+	return c.Webhook
+}
+
+// mapMessengers - mapper only fills defaults of fields, each messenger
+// instance is mapped again onto defaults of all types
+func (c *configuration) mapMessengers(table *lua.LTable, mapper *gluamapper.Mapper) error {
+	instances, ok := table.RawGetString("messengers").(*lua.LTable)
+	if !ok {
+		return nil
+	}
+
+	result := make([]MessengerConfig, 0, instances.Len())
+	var err error
+	instances.ForEach(func(_ lua.LValue, value lua.LValue) {
+		t, ok := value.(*lua.LTable)
+		if !ok || nil != err {
+			return
+		}
+
+		webhook := defaultWebhook
+		webhook.Headers = make(map[string]string)
+		for k, v := range defaultWebhook.Headers {
+			webhook.Headers[k] = v
+		}
+
+		m := MessengerConfig{
+			Slack:     defaultSlack,
+			Webhook:   webhook,
+			Email:     defaultEmail,
+			Telegram:  defaultTelegram,
+			PagerDuty: defaultPagerDuty,
+		}
+		err = mapper.Map(t, &m)
+		result = append(result, m)
+	})
+
+	c.Messengers = result
+	return err
+}
+
+// MessengersConfig - return named messenger instances
+func (c *configuration) MessengersConfig() []MessengerConfig {
+	return c.Messengers
+}
+
+// RoutesConfig - return routes of alerts to messengers
+func (c *configuration) RoutesConfig() []RouteConfig {
+	return c.Routes
+}
+
+// TemplatesConfig - return alert message templates overriding defaults,
+// keyed by alert condition or rule name
+func (c *configuration) TemplatesConfig() map[string]string {
+	return c.Templates
+}
+
+// DigestConfig - return digest config
+func (c *configuration) DigestConfig() DigestConfig {
+	return c.Digest
+}
+
+// AlertConfig - return alert config
+func (c *configuration) AlertConfig() AlertConfig {
+	return c.Alert
+}
+
+// HeightConfig - return height config
+func (c *configuration) HeightConfig() HeightConfig {
+	return c.Height
+}
+
 // Influx - return influx config
 func (c *configuration) Influx() InfluxDBConfig {
 	return c.InfluxDB
@@ -163,3 +677,8 @@ func (c *configuration) Influx() InfluxDBConfig {
 func (c *configuration) SlackConfig() SlackConfig {
 	return c.Slack
 }
+
+// CaptureConfig - return capture config
+func (c *configuration) CaptureConfig() CaptureConfig {
+	return c.Capture
+}
@@ -26,6 +26,8 @@ M.nodes = {
     public_key = "abcdef",
     chain = "bitmark",
     name = "name1",
+    subscribe = { "block", "heartbeat" },
+    tags = { "mainnet" },
   },
   {
     ip = "127.0.0.1",
@@ -53,6 +55,9 @@ M.logging = {
 }
 
 M.heartbeat_interval_second = 60
+M.heartbeat_silence_beats = 5
+
+M.transaction_confirm_timeout_minute = 30
 
 M.influxdb = {
   ip = "1.2.3.4",
@@ -65,6 +70,134 @@ M.influxdb = {
 M.slack = {
   token = "token",
   channel_id = "channelID",
+  bot = true,
+}
+
+M.capture = {
+  enable = true,
+  directory = "capture-dir",
+  file = "capture-file",
+  size = 1024,
+  count = 5,
+}
+
+M.height = {
+  interval_second = 30,
+  lag_threshold = 2,
+}
+
+M.alert = {
+  cooldown_minute = 60,
+}
+
+M.digest = {
+  daily = true,
+  weekday = "friday",
+  directory = "/tmp/digest",
+  messengers = { "email" },
+}
+
+M.alerts = {
+  {
+    name = "long-lag",
+    metric = "lag",
+    comparison = ">=",
+    threshold = 5,
+    duration_minute = 10,
+    hysteresis = 2,
+    severity = "critical",
+    nodes = { "node*" },
+    messengers = { "pagerduty" },
+  },
+  {
+    name = "slow",
+    metric = "confirm-time",
+    comparison = ">",
+    threshold = 30,
+  },
+}
+
+M.messengers = {
+  {
+    name = "mainnet-oncall",
+    type = "slack",
+    slack = {
+      token = "token1",
+      channel_id = "C1",
+    },
+  },
+  {
+    name = "ops-mail",
+    type = "email",
+    email = {
+      host = "smtp.example.com",
+      from = "monitor@example.com",
+      to = { "ops@example.com" },
+    },
+  },
+}
+
+M.routes = {
+  {
+    messengers = { "mainnet-oncall" },
+    severities = { "critical" },
+    chains = { "bitmark" },
+    continue = true,
+  },
+  {
+    messengers = { "ops-mail" },
+    tags = { "mainnet" },
+  },
+}
+
+M.templates = {
+  lag = "{{ .Node }} lags {{ .Summary.Blocks }} blocks",
+  ["drop-rate"] = "{{ .Node }} drops {{ percent .Summary.Value }}",
+}
+
+M.silences = {
+  {
+    name = "upgrade",
+    weekday = "tuesday",
+    start = "02:00",
+    duration_minute = 120,
+    timezone = "Asia/Taipei",
+    tags = { "mainnet" },
+    conditions = { "missing-blocks", "drop-rate" },
+  },
+}
+
+M.webhook = {
+  url = "http://localhost:8080/alert",
+  template = '{"content": {{ json .Message }}}',
+  headers = {
+    ["Content-Type"] = "application/json",
+    ["X-Token"] = "token",
+  },
+  secret = "secret",
+  retry = 5,
+}
+
+M.email = {
+  host = "smtp.example.com",
+  username = "user",
+  password = "password",
+  from = "monitor@example.com",
+  to = { "a@example.com", "b@example.com" },
+}
+
+M.pagerduty = {
+  routing_key = "routing",
+  conditions = { "fork", "lag" },
+  retry = 1,
+}
+
+M.telegram = {
+  token = "bot-token",
+  chats = {
+    critical = { "-1001", "-1002" },
+    warning = { "-1002" },
+  },
 }
 
 return M
@@ -98,6 +231,8 @@ func TestParse(t *testing.T) {
 		PublicKey:     "abcdef",
 		Chain:         "bitmark",
 		Name:          "name1",
+		Subscribe:     []string{"block", "heartbeat"},
+		Tags:          []string{"mainnet"},
 	}
 
 	node2 := configuration.NodeConfig{
@@ -123,8 +258,11 @@ func TestParse(t *testing.T) {
 	}
 
 	slack := configuration.SlackConfig{
+		URL:       "https://slack.com/api/",
 		Token:     "token",
 		ChannelID: "channelID",
+		Retry:     3,
+		Bot:       true,
 	}
 
 	assert.Equal(t, keys, actual.Keys, "wrong key")
@@ -194,6 +332,8 @@ func TestNodesConfig(t *testing.T) {
 		PublicKey:     "abcdef",
 		Chain:         "bitmark",
 		Name:          "name1",
+		Subscribe:     []string{"block", "heartbeat"},
+		Tags:          []string{"mainnet"},
 	}
 
 	node2 := configuration.NodeConfig{
@@ -238,6 +378,24 @@ func TestHeartbeatIntervalInSecond(t *testing.T) {
 	assert.Equal(t, 60, heartbeatInterval, "wrong heartbeat interval")
 }
 
+func TestHeartbeatSilenceInBeats(t *testing.T) {
+	setupConfigurationTestFile()
+	defer teardownTestFile()
+
+	config, _ := configuration.Parse(testFile)
+
+	assert.Equal(t, 5, config.HeartbeatSilenceInBeats(), "wrong heartbeat silence beats")
+}
+
+func TestConfirmTimeoutInMinute(t *testing.T) {
+	setupConfigurationTestFile()
+	defer teardownTestFile()
+
+	config, _ := configuration.Parse(testFile)
+
+	assert.Equal(t, 30, config.ConfirmTimeoutInMinute(), "wrong confirm timeout")
+}
+
 func TestInfluxDB(t *testing.T) {
 	setupConfigurationTestFile()
 	defer teardownTestFile()
@@ -262,9 +420,288 @@ func TestSlack(t *testing.T) {
 	config, _ := configuration.Parse(testFile)
 	slack := config.SlackConfig()
 	expected := configuration.SlackConfig{
+		URL:       "https://slack.com/api/",
 		Token:     "token",
 		ChannelID: "channelID",
+		Retry:     3,
+		Bot:       true,
+	}
+
+	assert.Equal(t, expected, slack, "wrong slack")
+}
+
+func TestCaptureConfig(t *testing.T) {
+	setupConfigurationTestFile()
+	defer teardownTestFile()
+
+	config, _ := configuration.Parse(testFile)
+	capture := config.CaptureConfig()
+	expected := configuration.CaptureConfig{
+		Enable:    true,
+		Directory: "capture-dir",
+		File:      "capture-file",
+		Size:      1024,
+		Count:     5,
+	}
+
+	assert.Equal(t, expected, capture, "wrong capture")
+}
+
+func TestHeightConfig(t *testing.T) {
+	setupConfigurationTestFile()
+	defer teardownTestFile()
+
+	config, _ := configuration.Parse(testFile)
+	expected := configuration.HeightConfig{
+		IntervalSecond: 30,
+		LagThreshold:   2,
+		LagMinute:      5,
+	}
+
+	assert.Equal(t, expected, config.HeightConfig(), "wrong height")
+}
+
+func TestAlertConfig(t *testing.T) {
+	setupConfigurationTestFile()
+	defer teardownTestFile()
+
+	config, _ := configuration.Parse(testFile)
+	expected := configuration.AlertConfig{
+		CooldownMinute: 60,
+		GroupSecond:    10,
+	}
+
+	assert.Equal(t, expected, config.AlertConfig(), "wrong alert")
+}
+
+func TestWebhookConfig(t *testing.T) {
+	setupConfigurationTestFile()
+	defer teardownTestFile()
+
+	config, _ := configuration.Parse(testFile)
+	expected := configuration.WebhookConfig{
+		URL:      "http://localhost:8080/alert",
+		Template: `{"content": {{ json .Message }}}`,
+		Headers: map[string]string{
+			"Content-Type": "application/json",
+			"X-Token":      "token",
+		},
+		Secret:             "secret",
+		SignatureHeader:    "X-Signature",
+		Retry:              5,
+		BackoffMillisecond: 1000,
+		TimeoutSecond:      10,
+	}
+
+	assert.Equal(t, expected, config.WebhookConfig(), "wrong webhook")
+}
+
+func TestEmailConfig(t *testing.T) {
+	setupConfigurationTestFile()
+	defer teardownTestFile()
+
+	config, _ := configuration.Parse(testFile)
+	expected := configuration.EmailConfig{
+		Host:          "smtp.example.com",
+		Port:          "587",
+		Username:      "user",
+		Password:      "password",
+		StartTLS:      true,
+		From:          "monitor@example.com",
+		To:            []string{"a@example.com", "b@example.com"},
+		Subject:       "bitmarkd broadcast monitor alert",
+		TimeoutSecond: 10,
+	}
+
+	assert.Equal(t, expected, config.EmailConfig(), "wrong email")
+}
+
+func TestPagerDutyConfig(t *testing.T) {
+	setupConfigurationTestFile()
+	defer teardownTestFile()
+
+	config, _ := configuration.Parse(testFile)
+	expected := configuration.PagerDutyConfig{
+		URL:                "https://events.pagerduty.com/v2/enqueue",
+		RoutingKey:         "routing",
+		Source:             "bitmarkd-broadcast-monitor",
+		Severity:           "critical",
+		Conditions:         []string{"fork", "lag"},
+		Retry:              1,
+		BackoffMillisecond: 1000,
+		TimeoutSecond:      10,
+	}
+
+	assert.Equal(t, expected, config.PagerDutyConfig(), "wrong pagerduty")
+}
+
+func TestTelegramConfig(t *testing.T) {
+	setupConfigurationTestFile()
+	defer teardownTestFile()
+
+	config, _ := configuration.Parse(testFile)
+	expected := configuration.TelegramConfig{
+		URL:   "https://api.telegram.org",
+		Token: "bot-token",
+		Chats: map[string][]string{
+			"critical": {"-1001", "-1002"},
+			"warning":  {"-1002"},
+		},
+		Retry:              3,
+		BackoffMillisecond: 1000,
+		TimeoutSecond:      10,
+	}
+
+	assert.Equal(t, expected, config.TelegramConfig(), "wrong telegram")
+}
+
+func TestDigestConfig(t *testing.T) {
+	setupConfigurationTestFile()
+	defer teardownTestFile()
+
+	config, _ := configuration.Parse(testFile)
+	expected := configuration.DigestConfig{
+		Daily:      true,
+		Weekly:     false,
+		Hour:       9,
+		Weekday:    "friday",
+		Directory:  "/tmp/digest",
+		Messengers: []string{"email"},
+	}
+
+	assert.Equal(t, expected, config.DigestConfig(), "wrong digest")
+}
+
+func TestRulesConfig(t *testing.T) {
+	setupConfigurationTestFile()
+	defer teardownTestFile()
+
+	config, _ := configuration.Parse(testFile)
+	expected := []configuration.RuleConfig{
+		{
+			Name:           "long-lag",
+			Metric:         "lag",
+			Comparison:     ">=",
+			Threshold:      5,
+			DurationMinute: 10,
+			Hysteresis:     2,
+			Severity:       "critical",
+			Nodes:          []string{"node*"},
+			Messengers:     []string{"pagerduty"},
+		},
+		{
+			Name:       "slow",
+			Metric:     "confirm-time",
+			Comparison: ">",
+			Threshold:  30,
+		},
+		{
+			Name:           "drop-rate",
+			Metric:         "drop-rate",
+			Comparison:     ">",
+			Threshold:      0.1,
+			DurationMinute: 2,
+			Hysteresis:     0.02,
+			Severity:       "warning",
+		},
+		{
+			Name:       "missing-blocks",
+			Metric:     "missing-blocks",
+			Comparison: ">",
+			Threshold:  0,
+			Severity:   "warning",
+		},
+		{
+			Name:       "fork-depth",
+			Metric:     "fork-depth",
+			Comparison: ">",
+			Threshold:  0,
+			Severity:   "warning",
+		},
+	}
+
+	assert.Equal(t, expected, config.RulesConfig(), "wrong rules")
+}
+
+func TestSilencesConfig(t *testing.T) {
+	setupConfigurationTestFile()
+	defer teardownTestFile()
+
+	config, _ := configuration.Parse(testFile)
+	expected := []configuration.SilenceConfig{
+		{
+			Name:           "upgrade",
+			Weekday:        "tuesday",
+			Start:          "02:00",
+			DurationMinute: 120,
+			Timezone:       "Asia/Taipei",
+			Tags:           []string{"mainnet"},
+			Conditions:     []string{"missing-blocks", "drop-rate"},
+		},
+	}
+
+	assert.Equal(t, expected, config.SilencesConfig(), "wrong silences")
+}
+
+func TestMessengersConfig(t *testing.T) {
+	setupConfigurationTestFile()
+	defer teardownTestFile()
+
+	config, _ := configuration.Parse(testFile)
+	actual := config.MessengersConfig()
+
+	assert.Equal(t, 2, len(actual), "wrong messenger count")
+	assert.Equal(t, "mainnet-oncall", actual[0].Name, "wrong name")
+	assert.Equal(t, "slack", actual[0].Type, "wrong type")
+	assert.Equal(t, configuration.SlackConfig{
+		URL:       "https://slack.com/api/",
+		Token:     "token1",
+		ChannelID: "C1",
+		Retry:     3,
+	}, actual[0].Slack, "wrong slack")
+
+	assert.Equal(t, "ops-mail", actual[1].Name, "wrong name")
+	assert.Equal(t, configuration.EmailConfig{
+		Host:          "smtp.example.com",
+		Port:          "587",
+		StartTLS:      true,
+		From:          "monitor@example.com",
+		To:            []string{"ops@example.com"},
+		Subject:       "bitmarkd broadcast monitor alert",
+		TimeoutSecond: 10,
+	}, actual[1].Email, "wrong email")
+}
+
+func TestRoutesConfig(t *testing.T) {
+	setupConfigurationTestFile()
+	defer teardownTestFile()
+
+	config, _ := configuration.Parse(testFile)
+	expected := []configuration.RouteConfig{
+		{
+			Messengers: []string{"mainnet-oncall"},
+			Severities: []string{"critical"},
+			Chains:     []string{"bitmark"},
+			Continue:   true,
+		},
+		{
+			Messengers: []string{"ops-mail"},
+			Tags:       []string{"mainnet"},
+		},
+	}
+
+	assert.Equal(t, expected, config.RoutesConfig(), "wrong routes")
+}
+
+func TestTemplatesConfig(t *testing.T) {
+	setupConfigurationTestFile()
+	defer teardownTestFile()
+
+	config, _ := configuration.Parse(testFile)
+	expected := map[string]string{
+		"lag":       "{{ .Node }} lags {{ .Summary.Blocks }} blocks",
+		"drop-rate": "{{ .Node }} drops {{ percent .Summary.Value }}",
 	}
 
-	assert.Equal(t, expected, slack, "wrong influxdb")
+	assert.Equal(t, expected, config.TemplatesConfig(), "wrong templates")
 }
@@ -5,7 +5,9 @@ import (
 	lua "github.com/yuin/gopher-lua"
 )
 
-func parseLuaConfigurationFile(fileName string, config interface{}) error {
+// parseLuaConfigurationFile - map table returned by lua file to config, then
+// hooks map parts of table mapper cannot fill with defaults
+func parseLuaConfigurationFile(fileName string, config interface{}, hooks ...func(*lua.LTable, *gluamapper.Mapper) error) error {
 	L := lua.NewState()
 	defer L.Close()
 
@@ -27,6 +29,15 @@ func parseLuaConfigurationFile(fileName string, config interface{}) error {
 	}
 
 	mapper := gluamapper.Mapper{Option: mapperOption}
-	err := mapper.Map(L.Get(L.GetTop()).(*lua.LTable), config)
-	return err
+	table := L.Get(L.GetTop()).(*lua.LTable)
+	if err := mapper.Map(table, config); nil != err {
+		return err
+	}
+
+	for _, hook := range hooks {
+		if err := hook(table, &mapper); nil != err {
+			return err
+		}
+	}
+	return nil
 }
@@ -0,0 +1,470 @@
+package digest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/configuration"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/fault"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/message"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/messengers"
+)
+
+// gauges of node, sampled at every check
+const (
+	// HeartbeatDropRate - heartbeat drop rate between 0 and 1
+	HeartbeatDropRate = "heartbeat-drop-rate"
+
+	// TransactionDropRate - transaction drop rate between 0 and 1
+	TransactionDropRate = "transaction-drop-rate"
+
+	// BlockDropRate - missing blocks over expected blocks between 0 and 1
+	BlockDropRate = "block-drop-rate"
+
+	// ConfirmTime - longest minutes from block generated to block received
+	ConfirmTime = "confirm-time"
+
+	// DisconnectTime - minutes since last broadcast received
+	DisconnectTime = "disconnect-time"
+
+	// Lag - blocks behind best height of same chain
+	Lag = "lag"
+)
+
+const (
+	daily  = "daily"
+	weekly = "weekly"
+
+	stateFile    = "digest.json"
+	checkMinute  = 1 * time.Minute
+	reportFormat = "2006-01-02 15:04 MST"
+	fileFormat   = "2006-01-02"
+)
+
+// Digest - keep history of nodes and report it periodically
+type Digest interface {
+	Fork(string, uint64, uint64)
+	Gauge(string, string, float64)
+	Loop([]interface{})
+}
+
+// Gauge - samples of metric
+type Gauge struct {
+	Count uint64  `json:"count"`
+	Sum   float64 `json:"sum"`
+	Max   float64 `json:"max"`
+}
+
+func (g *Gauge) add(value float64) {
+	if 0 == g.Count || value > g.Max {
+		g.Max = value
+	}
+	g.Count++
+	g.Sum += value
+}
+
+func (g *Gauge) average() float64 {
+	if 0 == g.Count {
+		return 0
+	}
+	return g.Sum / float64(g.Count)
+}
+
+// stats - history of node in period, forks are keyed by begin and end
+// block so fork kept in recorder window is counted once
+type stats struct {
+	Gauges map[string]*Gauge `json:"gauges"`
+	Forks  map[string]uint64 `json:"forks"`
+}
+
+func newStats() *stats {
+	return &stats{
+		Gauges: make(map[string]*Gauge),
+		Forks:  make(map[string]uint64),
+	}
+}
+
+// period - history since start of period
+type period struct {
+	Start time.Time         `json:"start"`
+	Nodes map[string]*stats `json:"nodes"`
+}
+
+func newPeriod(start time.Time) *period {
+	return &period{
+		Start: start,
+		Nodes: make(map[string]*stats),
+	}
+}
+
+func (p *period) of(node string) *stats {
+	s, ok := p.Nodes[node]
+	if !ok {
+		s = newStats()
+		p.Nodes[node] = s
+	}
+	return s
+}
+
+type digest struct {
+	sync.Mutex
+	config   configuration.DigestConfig
+	location *time.Location
+	weekday  time.Weekday
+	senders  map[string]messengers.Messenger
+	names    []string
+	periods  map[string]*period
+	now      func() time.Time
+}
+
+// New - digest of config, history of unfinished periods is loaded from
+// directory, reports are sent to messengers targeted by config
+func New(config configuration.DigestConfig, senders map[string]messengers.Messenger) (Digest, error) {
+	if 0 > config.Hour || 23 < config.Hour {
+		return nil, fault.InvalidDigestHour
+	}
+
+	weekday, ok := parseWeekday(config.Weekday)
+	if !ok {
+		return nil, fault.InvalidDigestWeekday
+	}
+
+	location, err := time.LoadLocation(config.Timezone)
+	if nil != err {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(senders))
+	for name := range senders {
+		if targets(config.Messengers, name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	d := &digest{
+		config:   config,
+		location: location,
+		weekday:  weekday,
+		senders:  senders,
+		names:    names,
+		periods:  make(map[string]*period),
+		now:      time.Now,
+	}
+
+	now := d.now()
+	for _, name := range d.enabled() {
+		d.periods[name] = newPeriod(now)
+	}
+	d.load()
+
+	return d, nil
+}
+
+func parseWeekday(s string) (time.Weekday, bool) {
+	for i := time.Sunday; i <= time.Saturday; i++ {
+		if strings.EqualFold(i.String(), s) {
+			return i, true
+		}
+	}
+	return time.Sunday, false
+}
+
+func targets(names []string, name string) bool {
+	if 0 == len(names) {
+		return true
+	}
+
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *digest) enabled() []string {
+	result := make([]string, 0, 2)
+	if d.config.Daily {
+		result = append(result, daily)
+	}
+	if d.config.Weekly {
+		result = append(result, weekly)
+	}
+	return result
+}
+
+// Gauge - sample of metric of node
+func (d *digest) Gauge(node string, metric string, value float64) {
+	d.Lock()
+	defer d.Unlock()
+
+	for _, p := range d.periods {
+		s := p.of(node)
+		g, ok := s.Gauges[metric]
+		if !ok {
+			g = &Gauge{}
+			s.Gauges[metric] = g
+		}
+		g.add(value)
+	}
+}
+
+// Fork - fork of node from begin to end block
+func (d *digest) Fork(node string, begin uint64, end uint64) {
+	if end < begin {
+		return
+	}
+
+	d.Lock()
+	defer d.Unlock()
+
+	k := fmt.Sprintf("%d-%d", begin, end)
+	for _, p := range d.periods {
+		p.of(node).Forks[k] = end - begin + 1
+	}
+}
+
+// Loop - report every due period until shutdown, history is saved at every
+// check and at shutdown so restart or crash keeps unfinished periods
+func (d *digest) Loop(args []interface{}) {
+	if 1 != len(args) {
+		fmt.Println("digest Loop wrong argument length")
+		return
+	}
+	shutdown := args[0].(<-chan struct{})
+
+	if 0 == len(d.periods) {
+		return
+	}
+
+	timer := time.NewTimer(checkMinute)
+	for {
+		select {
+		case <-shutdown:
+			d.save()
+			fmt.Println("terminate digest loop")
+			return
+
+		case <-timer.C:
+			d.reportDue()
+			d.save()
+			timer.Reset(checkMinute)
+		}
+	}
+}
+
+// reportDue - report and restart periods passing their end
+func (d *digest) reportDue() {
+	now := d.now()
+
+	d.Lock()
+	reports := make(map[string]string)
+	starts := make(map[string]time.Time)
+	for name, p := range d.periods {
+		if now.Before(d.end(name, p.Start)) {
+			continue
+		}
+		reports[name] = report(name, p, now, d.location)
+		starts[name] = p.Start
+		d.periods[name] = newPeriod(now)
+	}
+	d.Unlock()
+
+	if 0 == len(reports) {
+		return
+	}
+
+	for _, name := range []string{daily, weekly} {
+		r, ok := reports[name]
+		if !ok {
+			continue
+		}
+		d.write(name, starts[name], r)
+		d.send(r)
+	}
+}
+
+// end - first report time of period after start
+func (d *digest) end(name string, start time.Time) time.Time {
+	local := start.In(d.location)
+	end := time.Date(local.Year(), local.Month(), local.Day(), d.config.Hour, 0, 0, 0, d.location)
+
+	if weekly == name {
+		days := (int(d.weekday) - int(end.Weekday()) + 7) % 7
+		end = end.AddDate(0, 0, days)
+		if !end.After(start) {
+			end = end.AddDate(0, 0, 7)
+		}
+		return end
+	}
+
+	if !end.After(start) {
+		end = end.AddDate(0, 0, 1)
+	}
+	return end
+}
+
+func (d *digest) send(r string) {
+	for _, name := range d.names {
+		m := d.senders[name]
+		if nil == m || !m.Valid() {
+			continue
+		}
+
+		if err := m.Send(r, messengers.Info); nil != err {
+			fmt.Printf("send digest to %s with error: %s\n", name, err)
+		}
+	}
+}
+
+// write - report of period is saved as file named by period and its start
+func (d *digest) write(name string, start time.Time, r string) {
+	if err := os.MkdirAll(d.config.Directory, 0755); nil != err {
+		fmt.Printf("create digest directory with error: %s\n", err)
+		return
+	}
+
+	file := filepath.Join(
+		d.config.Directory,
+		fmt.Sprintf("%s-%s.txt", name, start.In(d.location).Format(fileFormat)),
+	)
+	if err := ioutil.WriteFile(file, []byte(r+"\n"), 0644); nil != err {
+		fmt.Printf("write digest %s with error: %s\n", file, err)
+	}
+}
+
+// save - history of unfinished periods, written to temporary file first so
+// crash while writing keeps previous history
+func (d *digest) save() {
+	d.Lock()
+	data, err := json.Marshal(d.periods)
+	d.Unlock()
+	if nil != err {
+		fmt.Printf("marshal digest with error: %s\n", err)
+		return
+	}
+
+	if err := os.MkdirAll(d.config.Directory, 0755); nil != err {
+		fmt.Printf("create digest directory with error: %s\n", err)
+		return
+	}
+
+	file := filepath.Join(d.config.Directory, stateFile)
+	if err := ioutil.WriteFile(file+".tmp", data, 0644); nil != err {
+		fmt.Printf("save digest with error: %s\n", err)
+		return
+	}
+
+	if err := os.Rename(file+".tmp", file); nil != err {
+		fmt.Printf("save digest with error: %s\n", err)
+	}
+}
+
+// load - history of enabled periods saved before, missing file is ignored
+func (d *digest) load() {
+	data, err := ioutil.ReadFile(filepath.Join(d.config.Directory, stateFile))
+	if nil != err {
+		return
+	}
+
+	saved := make(map[string]*period)
+	if err := json.Unmarshal(data, &saved); nil != err {
+		fmt.Printf("load digest with error: %s\n", err)
+		return
+	}
+
+	for name, p := range saved {
+		if _, ok := d.periods[name]; ok && nil != p.Nodes {
+			d.periods[name] = p
+		}
+	}
+}
+
+// report - one section of each node, nodes are sorted by name
+func report(name string, p *period, now time.Time, location *time.Location) string {
+	lines := []string{fmt.Sprintf(
+		"%s digest %s - %s",
+		name,
+		p.Start.In(location).Format(reportFormat),
+		now.In(location).Format(reportFormat),
+	)}
+
+	nodes := make([]string, 0, len(p.Nodes))
+	for node := range p.Nodes {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+
+	if 0 == len(nodes) {
+		lines = append(lines, "no data")
+	}
+
+	for _, node := range nodes {
+		lines = append(lines, fmt.Sprintf("%s:", node))
+		for _, l := range p.Nodes[node].lines() {
+			lines = append(lines, fmt.Sprintf("  %s", l))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// lines - metrics of node with samples
+func (s *stats) lines() []string {
+	lines := make([]string, 0)
+
+	rates := []struct {
+		metric string
+		title  string
+	}{
+		{HeartbeatDropRate, "heartbeat drop rate"},
+		{TransactionDropRate, "transaction drop rate"},
+		{BlockDropRate, "block drop rate"},
+	}
+	for _, r := range rates {
+		if g, ok := s.Gauges[r.metric]; ok {
+			lines = append(lines, fmt.Sprintf(
+				"%s: average %s, max %s",
+				r.title,
+				message.Percent(g.average()),
+				message.Percent(g.Max),
+			))
+		}
+	}
+
+	depth := uint64(0)
+	for _, d := range s.Forks {
+		if d > depth {
+			depth = d
+		}
+	}
+	lines = append(lines, fmt.Sprintf("forks: %d, deepest %d blocks", len(s.Forks), depth))
+
+	if g, ok := s.Gauges[ConfirmTime]; ok {
+		lines = append(lines, fmt.Sprintf("longest confirm: %s", minutes(g.Max)))
+	}
+
+	if g, ok := s.Gauges[DisconnectTime]; ok {
+		lines = append(lines, fmt.Sprintf("longest without broadcast: %s", minutes(g.Max)))
+	}
+
+	if g, ok := s.Gauges[Lag]; ok {
+		lines = append(lines, fmt.Sprintf(
+			"lag: average %s, max %s blocks",
+			message.Round(g.average(), 2),
+			message.Round(g.Max, 2),
+		))
+	}
+	return lines
+}
+
+func minutes(m float64) string {
+	return (time.Duration(m * float64(time.Minute))).Truncate(time.Second).String()
+}
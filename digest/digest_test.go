@@ -0,0 +1,150 @@
+package digest
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/configuration"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/fault"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/messengers"
+	"github.com/stretchr/testify/assert"
+)
+
+type testMessenger struct {
+	messages   []string
+	severities []string
+}
+
+func (t *testMessenger) Send(args ...interface{}) error {
+	t.messages = append(t.messages, fmt.Sprintf("%s", args[0]))
+	t.severities = append(t.severities, fmt.Sprintf("%s", args[1]))
+	return nil
+}
+
+func (t *testMessenger) Valid() bool {
+	return true
+}
+
+func setupDigest(t *testing.T) (*digest, *testMessenger, string) {
+	dir, _ := ioutil.TempDir("", "digest")
+	messenger := &testMessenger{}
+	other := &testMessenger{}
+
+	d, err := New(configuration.DigestConfig{
+		Daily:      true,
+		Weekly:     true,
+		Hour:       9,
+		Weekday:    "monday",
+		Directory:  dir,
+		Messengers: []string{"test"},
+	}, map[string]messengers.Messenger{"test": messenger, "other": other})
+	assert.Nil(t, err, "wrong error")
+
+	return d.(*digest), messenger, dir
+}
+
+func TestNewWhenInvalid(t *testing.T) {
+	_, err := New(configuration.DigestConfig{Hour: 24, Weekday: "monday"}, nil)
+	assert.Equal(t, fault.InvalidDigestHour, err, "wrong hour error")
+
+	_, err = New(configuration.DigestConfig{Hour: 9, Weekday: "someday"}, nil)
+	assert.Equal(t, fault.InvalidDigestWeekday, err, "wrong weekday error")
+
+	_, err = New(configuration.DigestConfig{Hour: 9, Weekday: "monday", Timezone: "Nowhere/Town"}, nil)
+	assert.NotNil(t, err, "wrong timezone error")
+}
+
+func TestEnd(t *testing.T) {
+	d, _, dir := setupDigest(t)
+	defer os.RemoveAll(dir)
+
+	// tuesday
+	start := time.Date(2019, time.December, 3, 10, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2019, time.December, 4, 9, 0, 0, 0, time.UTC), d.end(daily, start), "wrong daily end")
+	assert.Equal(t, time.Date(2019, time.December, 9, 9, 0, 0, 0, time.UTC), d.end(weekly, start), "wrong weekly end")
+
+	start = time.Date(2019, time.December, 3, 8, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2019, time.December, 3, 9, 0, 0, 0, time.UTC), d.end(daily, start), "wrong daily end before hour")
+}
+
+func TestReport(t *testing.T) {
+	d, _, dir := setupDigest(t)
+	defer os.RemoveAll(dir)
+
+	d.Gauge("a", TransactionDropRate, 0.1)
+	d.Gauge("a", TransactionDropRate, 0.3)
+	d.Gauge("a", BlockDropRate, 0)
+	d.Gauge("a", ConfirmTime, 45)
+	d.Gauge("a", DisconnectTime, 1.5)
+	d.Gauge("a", Lag, 1)
+	d.Gauge("a", Lag, 4)
+	d.Fork("a", 10, 12)
+	d.Fork("a", 10, 12)
+	d.Fork("a", 20, 20)
+	d.Gauge("b", HeartbeatDropRate, 0.05)
+
+	start := time.Date(2019, time.December, 2, 9, 0, 0, 0, time.UTC)
+	p := d.periods[daily]
+	p.Start = start
+
+	expected := "daily digest 2019-12-02 09:00 UTC - 2019-12-03 09:00 UTC\n" +
+		"a:\n" +
+		"  transaction drop rate: average 20%, max 30%\n" +
+		"  block drop rate: average 0%, max 0%\n" +
+		"  forks: 2, deepest 3 blocks\n" +
+		"  longest confirm: 45m0s\n" +
+		"  longest without broadcast: 1m30s\n" +
+		"  lag: average 2.5, max 4 blocks\n" +
+		"b:\n" +
+		"  heartbeat drop rate: average 5%, max 5%\n" +
+		"  forks: 0, deepest 0 blocks"
+	assert.Equal(t, expected, report(daily, p, start.Add(24*time.Hour), time.UTC), "wrong report")
+}
+
+func TestReportDue(t *testing.T) {
+	d, messenger, dir := setupDigest(t)
+	defer os.RemoveAll(dir)
+
+	start := time.Date(2019, time.December, 2, 10, 0, 0, 0, time.UTC)
+	d.periods[daily].Start = start
+	d.periods[weekly].Start = start
+	d.Gauge("a", Lag, 1)
+
+	d.now = func() time.Time { return start.Add(time.Hour) }
+	d.reportDue()
+	assert.Equal(t, 0, len(messenger.messages), "wrong report before due")
+
+	d.now = func() time.Time { return start.Add(23 * time.Hour) }
+	d.reportDue()
+	assert.Equal(t, 1, len(messenger.messages), "wrong report count")
+	assert.Equal(t, messengers.Info, messenger.severities[0], "wrong severity")
+	assert.Contains(t, messenger.messages[0], "daily digest", "wrong report")
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "daily-2019-12-02.txt"))
+	assert.Nil(t, err, "wrong report file")
+	assert.Contains(t, string(data), "lag: average 1, max 1 blocks", "wrong report file content")
+
+	assert.Equal(t, 0, len(d.periods[daily].Nodes), "wrong daily period not restarted")
+	assert.Equal(t, 1, len(d.periods[weekly].Nodes), "wrong weekly period restarted")
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	d, _, dir := setupDigest(t)
+	defer os.RemoveAll(dir)
+
+	d.Gauge("a", Lag, 3)
+	d.save()
+
+	_, err := os.Stat(filepath.Join(dir, stateFile+".tmp"))
+	assert.True(t, os.IsNotExist(err), "wrong temporary file left")
+
+	loaded, err := New(d.config, nil)
+	assert.Nil(t, err, "wrong error")
+
+	g := loaded.(*digest).periods[weekly].Nodes["a"].Gauges[Lag]
+	assert.Equal(t, float64(3), g.Max, "wrong loaded lag")
+}
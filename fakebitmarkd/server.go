@@ -0,0 +1,394 @@
+package fakebitmarkd
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/bitmark-inc/bitmarkd/blockdigest"
+	"github.com/bitmark-inc/bitmarkd/blockrecord"
+	"github.com/bitmark-inc/bitmarkd/difficulty"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/network"
+	zmq "github.com/pebbe/zmq4"
+)
+
+// Server - fake bitmarkd, CURVE secured PUB socket for broadcast and REP
+// socket answering info, height, block and block hash commands
+type Server struct {
+	sync.Mutex
+	blocks        map[uint64][]byte
+	broadcastPort string
+	chain         string
+	commandPort   string
+	height        uint64
+	normal        bool
+	publicKey     []byte
+	publisher     *zmq.Socket
+	replyDelay    time.Duration
+	responder     *zmq.Socket
+	shutdown      chan struct{}
+	version       string
+	wg            sync.WaitGroup
+}
+
+// Event - broadcast published by script after delay from previous event
+type Event struct {
+	Delay    time.Duration
+	Category string
+	Data     [][]byte
+}
+
+type info struct {
+	Version string `json:"version"`
+	Chain   string `json:"chain"`
+	Normal  bool   `json:"normal"`
+	Height  uint64 `json:"height"`
+}
+
+const (
+	zapDomain       = "fakebitmarkd"
+	localAddress    = "tcp://127.0.0.1:*"
+	responderPoll   = 100 * time.Millisecond
+	defaultVersion  = "0.0.0-fake"
+	heartbeatCmdStr = "heart"
+	blockCmdStr     = "block"
+	errorCmdStr     = "E"
+)
+
+// NewServer - start fake bitmarkd of chain listening on random local ports
+func NewServer(chain string) (*Server, error) {
+	if err := network.StartAuthentication(); nil != err {
+		return nil, err
+	}
+	zmq.AuthCurveAdd(zapDomain, zmq.CURVE_ALLOW_ANY)
+
+	public, secret, err := zmq.NewCurveKeypair()
+	if nil != err {
+		return nil, err
+	}
+
+	s := &Server{
+		blocks:    make(map[uint64][]byte),
+		chain:     chain,
+		normal:    true,
+		publicKey: []byte(zmq.Z85decode(public)),
+		shutdown:  make(chan struct{}),
+		version:   defaultVersion,
+	}
+
+	s.publisher, s.broadcastPort, err = newServerSocket(zmq.PUB, secret)
+	if nil != err {
+		return nil, err
+	}
+
+	s.responder, s.commandPort, err = newServerSocket(zmq.REP, secret)
+	if nil != err {
+		_ = s.publisher.Close()
+		return nil, err
+	}
+
+	s.wg.Add(1)
+	go s.respond()
+
+	return s, nil
+}
+
+func newServerSocket(socketType zmq.Type, secret string) (*zmq.Socket, string, error) {
+	socket, err := zmq.NewSocket(socketType)
+	if nil != err {
+		return nil, "", err
+	}
+
+	err = socket.ServerAuthCurve(zapDomain, secret)
+	if nil == err {
+		err = socket.SetLinger(0)
+	}
+	if nil == err {
+		err = socket.Bind(localAddress)
+	}
+	if nil != err {
+		_ = socket.Close()
+		return nil, "", err
+	}
+
+	endpoint, err := socket.GetLastEndpoint()
+	if nil != err {
+		_ = socket.Close()
+		return nil, "", err
+	}
+
+	_, port, err := net.SplitHostPort(endpoint[len("tcp://"):])
+	if nil != err {
+		_ = socket.Close()
+		return nil, "", err
+	}
+
+	return socket, port, nil
+}
+
+// NewKeys - generate monitor keys in format of configuration file
+func NewKeys() (public string, private string, err error) {
+	pub, sec, err := zmq.NewCurveKeypair()
+	if nil != err {
+		return "", "", err
+	}
+
+	public = "PUBLIC:" + hex.EncodeToString([]byte(zmq.Z85decode(pub)))
+	private = "PRIVATE:" + hex.EncodeToString([]byte(zmq.Z85decode(sec)))
+	return
+}
+
+// PublicKey - server public key in format of node config
+func (s *Server) PublicKey() string {
+	return hex.EncodeToString(s.publicKey)
+}
+
+// BroadcastPort - port of broadcast publisher
+func (s *Server) BroadcastPort() string {
+	return s.broadcastPort
+}
+
+// CommandPort - port of command responder
+func (s *Server) CommandPort() string {
+	return s.commandPort
+}
+
+// SetInfo - set version and mode reported by info command
+func (s *Server) SetInfo(version string, normal bool) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.version = version
+	s.normal = normal
+}
+
+// SetReplyDelay - delay every command reply, simulates slow node
+func (s *Server) SetReplyDelay(delay time.Duration) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.replyDelay = delay
+}
+
+// Publish - broadcast message of category
+func (s *Server) Publish(category string, data ...[]byte) error {
+	s.Lock()
+	defer s.Unlock()
+
+	parts := make([]interface{}, 0, len(data)+2)
+	parts = append(parts, s.chain, category)
+	for _, d := range data {
+		parts = append(parts, d)
+	}
+
+	_, err := s.publisher.SendMessage(parts...)
+	return err
+}
+
+// Heartbeat - broadcast heartbeat
+func (s *Server) Heartbeat() error {
+	return s.Publish(heartbeatCmdStr, []byte("beat"))
+}
+
+// PublishTransaction - broadcast packed transaction, category is one of
+// assets, issues and transfer
+func (s *Server) PublishTransaction(category string, packed []byte) error {
+	return s.Publish(category, packed)
+}
+
+// AddBlock - store block as new chain tip and broadcast it, returns packed block
+func (s *Server) AddBlock(number uint64, timestamp time.Time, transactions ...[]byte) ([]byte, error) {
+	header := blockrecord.Header{
+		Version:          blockrecord.Version,
+		TransactionCount: uint16(len(transactions)),
+		Number:           number,
+		Timestamp:        uint64(timestamp.Unix()),
+		Difficulty:       difficulty.New(),
+	}
+
+	s.Lock()
+	if previous, ok := s.blocks[number-1]; ok {
+		header.PreviousBlock = digest(previous)
+	}
+	s.Unlock()
+
+	packedHeader := header.Pack()
+	block := append([]byte{}, packedHeader[:]...)
+	for _, t := range transactions {
+		block = append(block, t...)
+	}
+
+	s.Lock()
+	s.blocks[number] = block
+	s.height = number
+	s.Unlock()
+
+	return block, s.Publish(blockCmdStr, block)
+}
+
+// Play - publish script events in order, returns early when server is closed
+func (s *Server) Play(script []Event) error {
+	for _, e := range script {
+		select {
+		case <-s.shutdown:
+			return nil
+		case <-time.After(e.Delay):
+		}
+
+		if err := s.Publish(e.Category, e.Data...); nil != err {
+			return err
+		}
+	}
+	return nil
+}
+
+// Flood - publish count messages of category at rate per second, rate 0
+// publishes without pause, useful as load generator
+func (s *Server) Flood(category string, data []byte, rate int, count int) error {
+	var interval time.Duration
+	if 0 < rate {
+		interval = time.Second / time.Duration(rate)
+	}
+
+	start := time.Now()
+	for i := 0; i < count; i++ {
+		if err := s.Publish(category, data); nil != err {
+			return err
+		}
+
+		if 0 == interval {
+			continue
+		}
+		if wait := time.Duration(i+1)*interval - time.Since(start); 0 < wait {
+			time.Sleep(wait)
+		}
+	}
+	return nil
+}
+
+// Close - stop responder and close sockets
+func (s *Server) Close() error {
+	close(s.shutdown)
+	s.wg.Wait()
+
+	s.Lock()
+	defer s.Unlock()
+
+	err := s.publisher.Close()
+	if closeErr := s.responder.Close(); nil == err {
+		err = closeErr
+	}
+	return err
+}
+
+func (s *Server) respond() {
+	defer s.wg.Done()
+
+	poller := zmq.NewPoller()
+	poller.Add(s.responder, zmq.POLLIN)
+
+	for {
+		select {
+		case <-s.shutdown:
+			return
+		default:
+		}
+
+		polled, err := poller.Poll(responderPoll)
+		if nil != err || 0 == len(polled) {
+			continue
+		}
+
+		data, err := s.responder.RecvMessageBytes(0)
+		if nil != err {
+			continue
+		}
+
+		s.Lock()
+		delay := s.replyDelay
+		s.Unlock()
+
+		if 0 < delay {
+			select {
+			case <-s.shutdown:
+				return
+			case <-time.After(delay):
+			}
+		}
+
+		reply := s.process(data)
+		parts := make([]interface{}, 0, len(reply))
+		for _, r := range reply {
+			parts = append(parts, r)
+		}
+		_, _ = s.responder.SendMessage(parts...)
+	}
+}
+
+// process - reply of request, same format as bitmarkd listener
+func (s *Server) process(data [][]byte) [][]byte {
+	if 2 > len(data) {
+		return errorReply(fmt.Errorf("packet too short"))
+	}
+
+	if s.chain != string(data[0]) {
+		return errorReply(fmt.Errorf("invalid chain: %q", data[0]))
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	cmd := string(data[1])
+	parameters := data[2:]
+
+	switch cmd {
+	case "I":
+		result, err := json.Marshal(info{
+			Version: s.version,
+			Chain:   s.chain,
+			Normal:  s.normal,
+			Height:  s.height,
+		})
+		if nil != err {
+			return errorReply(err)
+		}
+		return [][]byte{data[1], result}
+
+	case "N":
+		result := make([]byte, 8)
+		binary.BigEndian.PutUint64(result, s.height)
+		return [][]byte{data[1], result}
+
+	case "B", "H":
+		if 1 != len(parameters) || 8 != len(parameters[0]) {
+			return errorReply(fmt.Errorf("missing parameters"))
+		}
+
+		block, ok := s.blocks[binary.BigEndian.Uint64(parameters[0])]
+		if !ok {
+			return errorReply(fmt.Errorf("block not found"))
+		}
+
+		if "B" == cmd {
+			return [][]byte{data[1], block}
+		}
+		d := digest(block)
+		return [][]byte{data[1], d[:]}
+	}
+
+	return errorReply(fmt.Errorf("unknown command: %q", cmd))
+}
+
+func digest(block []byte) blockdigest.Digest {
+	var packed blockrecord.PackedHeader
+	copy(packed[:], block)
+	return packed.Digest()
+}
+
+func errorReply(err error) [][]byte {
+	return [][]byte{[]byte(errorCmdStr), []byte(err.Error())}
+}
@@ -0,0 +1,42 @@
+package fakebitmarkd
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+
+	"github.com/bitmark-inc/bitmarkd/account"
+	"github.com/bitmark-inc/bitmarkd/chain"
+	"github.com/bitmark-inc/bitmarkd/transactionrecord"
+	"golang.org/x/crypto/ed25519"
+)
+
+// NewIssue - packed bitmark issue signed by random owner, unique by random nonce
+func NewIssue(blockchain string) ([]byte, error) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if nil != err {
+		return nil, err
+	}
+
+	nonce := make([]byte, 8)
+	if _, err = rand.Read(nonce); nil != err {
+		return nil, err
+	}
+
+	owner := &account.Account{
+		AccountInterface: &account.ED25519Account{
+			Test:      chain.Bitmark != blockchain,
+			PublicKey: public,
+		},
+	}
+
+	issue := &transactionrecord.BitmarkIssue{
+		Owner: owner,
+		Nonce: binary.BigEndian.Uint64(nonce),
+	}
+
+	// pack returns unsigned message when signature is wrong
+	unsigned, _ := issue.Pack(owner)
+	issue.Signature = ed25519.Sign(private, unsigned)
+
+	return issue.Pack(owner)
+}
@@ -26,4 +26,97 @@ var (
 
 	// InsufficientSlackSendParameter - insufficient slack send parameter
 	InsufficientSlackSendParameter = errors.New("insufficient slack send parameter")
+
+	// InsufficientSendParameter - insufficient messenger send parameter
+	InsufficientSendParameter = errors.New("insufficient send parameter")
+
+	// StartTLSNotSupported - smtp server does not support STARTTLS
+	StartTLSNotSupported = errors.New("smtp server does not support STARTTLS")
+
+	// InvalidCaptureConfig - invalid capture config
+	InvalidCaptureConfig = errors.New("invalid capture config")
+
+	// InvalidCaptureFile - invalid capture file
+	InvalidCaptureFile = errors.New("invalid capture file")
+
+	// InvalidSubscription - invalid subscribe category
+	InvalidSubscription = errors.New("invalid subscribe category")
+
+	// CaptureClosed - capture writer closed
+	CaptureClosed = errors.New("capture closed")
+
+	// WrongReplyPrefix - reply prefix differs from request command
+	WrongReplyPrefix = errors.New("wrong reply prefix")
+
+	// ShortReply - reply has less parts or bytes than expected
+	ShortReply = errors.New("reply too short")
+
+	// DecodeReplyFailed - reply cannot be decoded
+	DecodeReplyFailed = errors.New("decode reply failed")
+
+	// InvalidRuleName - rule name empty or duplicated
+	InvalidRuleName = errors.New("invalid rule name")
+
+	// InvalidRuleMetric - rule metric not supported
+	InvalidRuleMetric = errors.New("invalid rule metric")
+
+	// InvalidRuleComparison - rule comparison not supported
+	InvalidRuleComparison = errors.New("invalid rule comparison")
+
+	// InvalidRuleHysteresis - rule hysteresis negative or on equality
+	InvalidRuleHysteresis = errors.New("invalid rule hysteresis")
+
+	// InvalidRuleSeverity - rule severity not supported
+	InvalidRuleSeverity = errors.New("invalid rule severity")
+
+	// InvalidRuleMessenger - rule targets messenger not exist
+	InvalidRuleMessenger = errors.New("invalid rule messenger")
+
+	// InvalidRoute - route without messenger or with unknown severity
+	InvalidRoute = errors.New("invalid route")
+
+	// InvalidRouteMessenger - route targets messenger not exist
+	InvalidRouteMessenger = errors.New("invalid route messenger")
+
+	// InvalidMessengerName - messenger name empty, duplicated or reserved
+	InvalidMessengerName = errors.New("invalid messenger name")
+
+	// InvalidMessengerType - messenger type not supported
+	InvalidMessengerType = errors.New("invalid messenger type")
+
+	// InvalidSilenceName - silence name empty or duplicated
+	InvalidSilenceName = errors.New("invalid silence name")
+
+	// InvalidSilenceWeekday - silence weekday is not english name of day
+	InvalidSilenceWeekday = errors.New("invalid silence weekday")
+
+	// InvalidSilenceStart - silence start is not hh:mm
+	InvalidSilenceStart = errors.New("invalid silence start")
+
+	// InvalidSilenceDuration - silence duration not positive or longer than a week
+	InvalidSilenceDuration = errors.New("invalid silence duration")
+
+	// UnknownSilence - silence not exist
+	UnknownSilence = errors.New("unknown silence")
+
+	// UnknownIncident - no open incident of node and condition
+	UnknownIncident = errors.New("unknown incident")
+
+	// InvalidDigestHour - digest hour not between 0 and 23
+	InvalidDigestHour = errors.New("invalid digest hour")
+
+	// InvalidDigestWeekday - digest weekday is not english name of day
+	InvalidDigestWeekday = errors.New("invalid digest weekday")
+
+	// UnknownTemplate - no message template of alert condition
+	UnknownTemplate = errors.New("unknown template")
+
+	// UnknownNode - node not in configuration
+	UnknownNode = errors.New("unknown node")
+
+	// RequestTimeout - no reply before deadline
+	RequestTimeout = errors.New("request timeout")
+
+	// RecordNotFound - remote replies requested record does not exist
+	RecordNotFound = errors.New("record not found")
 )
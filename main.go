@@ -6,6 +6,7 @@ import (
 	"os"
 	"os/signal"
 
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/capture"
 	"github.com/jamieabc/bitmarkd-broadcast-monitor/db"
 
 	"github.com/jamieabc/bitmarkd-broadcast-monitor/fault"
@@ -19,11 +20,15 @@ import (
 )
 
 var (
-	configFile string
+	configFile  string
+	replayPath  string
+	replaySpeed float64
 )
 
 func init() {
 	flag.StringVar(&configFile, "c", "", "config file")
+	flag.StringVar(&replayPath, "r", "", "replay capture file or directory instead of connecting to nodes")
+	flag.Float64Var(&replaySpeed, "s", 1, "replay speed, 1 is real time, 0 is as fast as possible")
 }
 
 func main() {
@@ -56,6 +61,11 @@ func main() {
 	log := logger.New("main")
 	defer log.Info("shutdown...")
 
+	if "" != replayPath {
+		replay(config, log)
+		return
+	}
+
 	log.Info("auth zmq")
 
 	err = zmqAuth()
@@ -91,6 +101,39 @@ func main() {
 	return
 }
 
+func replay(config configuration.Configuration, log *logger.L) {
+	log.Infof("replay %s", replayPath)
+	reader, err := capture.NewReader(replayPath)
+	if nil != err {
+		log.Errorf("open capture with error: %s", err)
+		return
+	}
+	defer reader.Close()
+
+	n, err := nodes.InitialiseReplay(config, reader, replaySpeed)
+	if nil != err {
+		log.Errorf("initialise replay with error: %s", err)
+		return
+	}
+
+	finish := make(chan struct{})
+	go func() {
+		n.Monitor()
+		close(finish)
+	}()
+
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, os.Interrupt)
+	select {
+	case <-ch:
+		fmt.Println("receive interrupt")
+	case <-finish:
+		fmt.Println("finish replay")
+	}
+	n.StopMonitor()
+	log.Flush()
+}
+
 func parseFlag() error {
 	flag.Parse()
 
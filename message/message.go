@@ -0,0 +1,135 @@
+package message
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/configuration"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/fault"
+)
+
+// Data - fields of alert message template, summary is structured summary
+// of alert condition, config and tags are empty for alert of many nodes
+type Data struct {
+	Node      string
+	Condition string
+	Config    configuration.NodeConfig
+	Tags      []string
+	Summary   interface{}
+}
+
+// Templates - alert message templates keyed by alert condition
+type Templates interface {
+	Has(string) bool
+	Render(string, Data) (string, error)
+}
+
+type templates struct {
+	defaults  map[string]*template.Template
+	overrides map[string]*template.Template
+}
+
+var funcs = template.FuncMap{
+	"duration": duration,
+	"join":     join,
+	"number":   number,
+	"percent":  Percent,
+}
+
+// New - templates of defaults replaced by overrides, default template is
+// used when override fails to render
+func New(defaults map[string]string, overrides map[string]string) (Templates, error) {
+	t := &templates{
+		defaults:  make(map[string]*template.Template),
+		overrides: make(map[string]*template.Template),
+	}
+
+	for key, text := range defaults {
+		parsed, err := template.New(key).Funcs(funcs).Parse(text)
+		if nil != err {
+			return nil, err
+		}
+		t.defaults[key] = parsed
+	}
+
+	for key, text := range overrides {
+		parsed, err := template.New(key).Funcs(funcs).Parse(text)
+		if nil != err {
+			fmt.Printf("parse template %s with error: %s\n", key, err)
+			return nil, err
+		}
+		t.overrides[key] = parsed
+	}
+	return t, nil
+}
+
+// Has - template of key is configured
+func (t *templates) Has(key string) bool {
+	_, isDefault := t.defaults[key]
+	_, isOverride := t.overrides[key]
+	return isDefault || isOverride
+}
+
+// Render - message of data rendered by template of key
+func (t *templates) Render(key string, data Data) (string, error) {
+	if override, ok := t.overrides[key]; ok {
+		msg, err := execute(override, data)
+		if nil == err {
+			return msg, nil
+		}
+		fmt.Printf("render template %s with error: %s, use default\n", key, err)
+	}
+
+	if d, ok := t.defaults[key]; ok {
+		return execute(d, data)
+	}
+	return "", fault.UnknownTemplate
+}
+
+func execute(t *template.Template, data Data) (string, error) {
+	var str strings.Builder
+	if err := t.Execute(&str, data); nil != err {
+		return "", err
+	}
+	return str.String(), nil
+}
+
+// duration - truncated to second
+func duration(d time.Duration) string {
+	return d.Truncate(time.Second).String()
+}
+
+// join - elements of slice joined by comma
+func join(list interface{}) string {
+	v := reflect.ValueOf(list)
+	if reflect.Slice != v.Kind() && reflect.Array != v.Kind() {
+		return fmt.Sprint(list)
+	}
+
+	parts := make([]string, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		parts = append(parts, fmt.Sprint(v.Index(i).Interface()))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// number - rounded to 4 decimals without trailing zeros
+func number(v float64) string {
+	return Round(v, 4)
+}
+
+// Round - value rounded to decimals without trailing zeros
+func Round(v float64, decimals int) string {
+	scale := math.Pow10(decimals)
+	return strconv.FormatFloat(math.Round(v*scale)/scale, 'f', -1, 64)
+}
+
+// Percent - rate between 0 and 1 as percent with 2 decimals
+func Percent(rate float64) string {
+	return Round(rate*100, 2) + "%"
+}
@@ -0,0 +1,81 @@
+package message_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/configuration"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/fault"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/message"
+	"github.com/stretchr/testify/assert"
+)
+
+type summary struct {
+	Blocks  uint64
+	Lasting time.Duration
+	Rate    float64
+	Value   float64
+}
+
+var defaults = map[string]string{
+	"lag": "{{ .Summary.Blocks }} blocks behind",
+}
+
+func TestRenderDefault(t *testing.T) {
+	tmpls, err := message.New(defaults, nil)
+	assert.Nil(t, err, "wrong error")
+
+	msg, err := tmpls.Render("lag", message.Data{Node: "a", Summary: summary{Blocks: 3}})
+	assert.Nil(t, err, "wrong render error")
+	assert.Equal(t, "3 blocks behind", msg, "wrong message")
+}
+
+func TestRenderOverride(t *testing.T) {
+	tmpls, _ := message.New(defaults, map[string]string{
+		"lag": "{{ .Node }} ({{ .Config.IP }}, {{ join .Tags }}) {{ .Condition }} for {{ duration .Summary.Lasting }}, drop {{ percent .Summary.Rate }}, value {{ number .Summary.Value }}",
+	})
+
+	msg, err := tmpls.Render("lag", message.Data{
+		Node:      "a",
+		Condition: "lag",
+		Config:    configuration.NodeConfig{IP: "127.0.0.1"},
+		Tags:      []string{"mainnet", "asia"},
+		Summary: summary{
+			Lasting: 90*time.Second + 300*time.Millisecond,
+			Rate:    0.12345,
+			Value:   1.234567,
+		},
+	})
+	assert.Nil(t, err, "wrong render error")
+	assert.Equal(t, "a (127.0.0.1, mainnet, asia) lag for 1m30s, drop 12.35%, value 1.2346", msg, "wrong message")
+}
+
+func TestRenderOverrideFallbackToDefault(t *testing.T) {
+	tmpls, _ := message.New(defaults, map[string]string{
+		"lag": "{{ .Summary.Unknown }}",
+	})
+
+	msg, err := tmpls.Render("lag", message.Data{Summary: summary{Blocks: 3}})
+	assert.Nil(t, err, "wrong render error")
+	assert.Equal(t, "3 blocks behind", msg, "wrong fallback message")
+}
+
+func TestRenderUnknownTemplate(t *testing.T) {
+	tmpls, _ := message.New(defaults, nil)
+
+	assert.False(t, tmpls.Has("fork"), "wrong unknown template")
+	_, err := tmpls.Render("fork", message.Data{})
+	assert.Equal(t, fault.UnknownTemplate, err, "wrong error")
+}
+
+func TestNewWithInvalidOverride(t *testing.T) {
+	_, err := message.New(defaults, map[string]string{"lag": "{{ .Node "})
+	assert.NotNil(t, err, "wrong parse error")
+}
+
+func TestRound(t *testing.T) {
+	assert.Equal(t, "0.1235", message.Round(0.123456, 4), "wrong 4 decimals")
+	assert.Equal(t, "2.5", message.Round(2.5, 2), "wrong trailing zeros")
+	assert.Equal(t, "3", message.Round(3, 2), "wrong integer")
+	assert.Equal(t, "12.35%", message.Percent(0.123456), "wrong percent")
+}
@@ -0,0 +1,168 @@
+package messengers
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"html/template"
+	"mime/multipart"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/configuration"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/fault"
+)
+
+type email struct {
+	config configuration.EmailConfig
+}
+
+var htmlTemplate = template.Must(template.New("email").Parse(
+	`<html><body><ul>{{ range . }}<li>{{ . }}</li>{{ end }}</ul></body></html>`,
+))
+
+// NewEmail - create smtp messenger sending to all recipients
+func NewEmail(config configuration.EmailConfig) Messenger {
+	return &email{config: config}
+}
+
+// Valid - check if smtp server, sender and recipients are configured
+func (e *email) Valid() bool {
+	return "" != e.config.Host && "" != e.config.From && 0 < len(e.config.To)
+}
+
+// Send - send message as plain text and html alternatives
+func (e *email) Send(args ...interface{}) error {
+	if 1 > len(args) {
+		return fault.InsufficientSendParameter
+	}
+
+	message, ok := args[0].(string)
+	if !ok {
+		return fault.InvalidArguments
+	}
+
+	body, err := e.compose(message, time.Now())
+	if nil != err {
+		return err
+	}
+
+	return e.deliver(body)
+}
+
+// deliver - deadline of connection bounds whole smtp session, unresponsive
+// server does not block sender
+func (e *email) deliver(body []byte) error {
+	timeout := time.Duration(e.config.TimeoutSecond) * time.Second
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(e.config.Host, e.config.Port), timeout)
+	if nil != err {
+		return err
+	}
+
+	if 0 < timeout {
+		if err = conn.SetDeadline(time.Now().Add(timeout)); nil != err {
+			conn.Close()
+			return err
+		}
+	}
+
+	c, err := smtp.NewClient(conn, e.config.Host)
+	if nil != err {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if e.config.StartTLS {
+		if ok, _ := c.Extension("STARTTLS"); !ok {
+			return fault.StartTLSNotSupported
+		}
+		if err = c.StartTLS(&tls.Config{ServerName: e.config.Host}); nil != err {
+			return err
+		}
+	}
+
+	if "" != e.config.Username {
+		auth := smtp.PlainAuth("", e.config.Username, e.config.Password, e.config.Host)
+		if err = c.Auth(auth); nil != err {
+			return err
+		}
+	}
+
+	if err = c.Mail(e.config.From); nil != err {
+		return err
+	}
+
+	for _, to := range e.config.To {
+		if err = c.Rcpt(to); nil != err {
+			return err
+		}
+	}
+
+	w, err := c.Data()
+	if nil != err {
+		return err
+	}
+
+	if _, err = w.Write(body); nil != err {
+		return err
+	}
+
+	if err = w.Close(); nil != err {
+		return err
+	}
+
+	return c.Quit()
+}
+
+// compose - mail with plain text and html parts, each line of message is an
+// item of html list
+func (e *email) compose(message string, now time.Time) ([]byte, error) {
+	var body bytes.Buffer
+	parts := multipart.NewWriter(&body)
+
+	var msg bytes.Buffer
+	msg.WriteString(fmt.Sprintf("From: %s\r\n", e.config.From))
+	msg.WriteString(fmt.Sprintf("To: %s\r\n", strings.Join(e.config.To, ", ")))
+	msg.WriteString(fmt.Sprintf("Subject: %s\r\n", e.config.Subject))
+	msg.WriteString(fmt.Sprintf("Date: %s\r\n", now.Format(time.RFC1123Z)))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString(fmt.Sprintf("Content-Type: multipart/alternative; boundary=%q\r\n\r\n", parts.Boundary()))
+
+	text, err := parts.CreatePart(textproto.MIMEHeader{
+		"Content-Type": {"text/plain; charset=utf-8"},
+	})
+	if nil != err {
+		return nil, err
+	}
+	if _, err = text.Write([]byte(message)); nil != err {
+		return nil, err
+	}
+
+	html, err := parts.CreatePart(textproto.MIMEHeader{
+		"Content-Type": {"text/html; charset=utf-8"},
+	})
+	if nil != err {
+		return nil, err
+	}
+
+	lines := make([]string, 0)
+	for _, line := range strings.Split(message, "\n") {
+		if trimmed := strings.TrimSpace(line); "" != trimmed {
+			lines = append(lines, trimmed)
+		}
+	}
+	if err = htmlTemplate.Execute(html, lines); nil != err {
+		return nil, err
+	}
+
+	if err = parts.Close(); nil != err {
+		return nil, err
+	}
+
+	msg.Write(body.Bytes())
+	return msg.Bytes(), nil
+}
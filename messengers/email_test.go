@@ -0,0 +1,177 @@
+package messengers_test
+
+import (
+	"encoding/base64"
+	"net"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/configuration"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/fault"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/messengers"
+	"github.com/stretchr/testify/assert"
+)
+
+// testSMTPServer - smtp stand-in accepting one session at a time, without
+// STARTTLS
+type testSMTPServer struct {
+	sync.Mutex
+	listener net.Listener
+	auth     string
+	from     string
+	to       []string
+	data     string
+}
+
+func setupSMTPServer(t *testing.T) *testSMTPServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if nil != err {
+		t.Fatalf("listen with error: %s", err)
+	}
+
+	s := &testSMTPServer{listener: listener}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if nil != err {
+				return
+			}
+			s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *testSMTPServer) serve(conn net.Conn) {
+	defer conn.Close()
+
+	tp := textproto.NewConn(conn)
+	_ = tp.PrintfLine("220 localhost ESMTP")
+
+	for {
+		line, err := tp.ReadLine()
+		if nil != err {
+			return
+		}
+
+		s.Lock()
+		switch cmd := strings.ToUpper(strings.Fields(line)[0]); cmd {
+		case "EHLO", "HELO":
+			_ = tp.PrintfLine("250-localhost")
+			_ = tp.PrintfLine("250 AUTH PLAIN")
+		case "AUTH":
+			decoded, _ := base64.StdEncoding.DecodeString(strings.Fields(line)[2])
+			s.auth = string(decoded)
+			_ = tp.PrintfLine("235 authenticated")
+		case "MAIL":
+			s.from = line
+			_ = tp.PrintfLine("250 ok")
+		case "RCPT":
+			s.to = append(s.to, line)
+			_ = tp.PrintfLine("250 ok")
+		case "DATA":
+			_ = tp.PrintfLine("354 go ahead")
+			data, _ := tp.ReadDotBytes()
+			s.data = string(data)
+			_ = tp.PrintfLine("250 ok")
+		case "QUIT":
+			_ = tp.PrintfLine("221 bye")
+			s.Unlock()
+			return
+		default:
+			_ = tp.PrintfLine("502 not implemented")
+		}
+		s.Unlock()
+	}
+}
+
+func (s *testSMTPServer) config() configuration.EmailConfig {
+	_, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	return configuration.EmailConfig{
+		Host:     "127.0.0.1",
+		Port:     port,
+		Username: "user",
+		Password: "password",
+		From:     "monitor@example.com",
+		To:       []string{"a@example.com", "b@example.com"},
+		Subject:  "alert",
+	}
+}
+
+func TestEmailValid(t *testing.T) {
+	assert.False(t, messengers.NewEmail(configuration.EmailConfig{}).Valid(), "wrong valid")
+	assert.True(t, messengers.NewEmail(configuration.EmailConfig{
+		Host: "localhost",
+		From: "monitor@example.com",
+		To:   []string{"a@example.com"},
+	}).Valid(), "wrong invalid")
+}
+
+func TestEmailSend(t *testing.T) {
+	s := setupSMTPServer(t)
+	defer s.listener.Close()
+
+	e := messengers.NewEmail(s.config())
+	err := e.Send("lag on 2 nodes:\n  a 5 blocks behind\n  b <6> blocks behind")
+	assert.Nil(t, err, "wrong error")
+
+	s.Lock()
+	defer s.Unlock()
+
+	assert.Equal(t, "\x00user\x00password", s.auth, "wrong auth")
+	assert.Equal(t, "MAIL FROM:<monitor@example.com>", s.from, "wrong sender")
+	assert.Equal(t, []string{"RCPT TO:<a@example.com>", "RCPT TO:<b@example.com>"}, s.to, "wrong recipients")
+	assert.Contains(t, s.data, "Subject: alert", "wrong subject")
+	assert.Contains(t, s.data, "To: a@example.com, b@example.com", "wrong to header")
+	assert.Contains(t, s.data, "multipart/alternative", "wrong content type")
+	assert.Contains(t, s.data, "text/plain", "wrong plain text part")
+	assert.Contains(t, s.data, "  b <6> blocks behind", "wrong plain text")
+	assert.Contains(t, s.data, "text/html", "wrong html part")
+	assert.Contains(t, s.data, "<li>b &lt;6&gt; blocks behind</li>", "wrong html")
+}
+
+func TestEmailSendWhenStartTLSNotSupported(t *testing.T) {
+	s := setupSMTPServer(t)
+	defer s.listener.Close()
+
+	config := s.config()
+	config.StartTLS = true
+
+	err := messengers.NewEmail(config).Send("message")
+	assert.Equal(t, fault.StartTLSNotSupported, err, "wrong error")
+}
+
+func TestEmailSendWhenServerNotRespond(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if nil != err {
+		t.Fatalf("listen with error: %s", err)
+	}
+	defer listener.Close()
+
+	// accept connection without greeting
+	go func() {
+		conn, err := listener.Accept()
+		if nil != err {
+			return
+		}
+		defer conn.Close()
+		<-time.After(3 * time.Second)
+	}()
+
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+	config := configuration.EmailConfig{
+		Host:          "127.0.0.1",
+		Port:          port,
+		From:          "monitor@example.com",
+		To:            []string{"a@example.com"},
+		TimeoutSecond: 1,
+	}
+
+	start := time.Now()
+	err = messengers.NewEmail(config).Send("message")
+	assert.NotNil(t, err, "wrong error")
+	assert.True(t, time.Since(start) < 2*time.Second, "wrong timeout")
+}
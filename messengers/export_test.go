@@ -0,0 +1,11 @@
+package messengers
+
+import "time"
+
+// SetSlackThreadIdle - idle time of slack thread before it is forgotten,
+// returns previous one
+func SetSlackThreadIdle(idle time.Duration) time.Duration {
+	previous := slackThreadIdle
+	slackThreadIdle = idle
+	return previous
+}
@@ -1,5 +1,10 @@
 package messengers
 
+import (
+	"fmt"
+	"strings"
+)
+
 // Messenger - interface for messenger
 type Messenger interface {
 	Sender
@@ -15,3 +20,74 @@ type Sender interface {
 type Validator interface {
 	Valid() bool
 }
+
+// severities of message, sent as optional second argument of Send, optional
+// third argument is []IncidentEvent of alerts in message
+const (
+	Critical = "critical"
+	Warning  = "warning"
+	Info     = "info"
+)
+
+// incident actions
+const (
+	Trigger     = "trigger"
+	Acknowledge = "acknowledge"
+	Resolve     = "resolve"
+)
+
+// IncidentEvent - change of incident, key is same for whole life of incident,
+// action is empty for one-off alert
+type IncidentEvent struct {
+	Action    string
+	Key       string
+	Node      string
+	Condition string
+	Summary   string
+}
+
+// IncidentMessenger - interface for messenger opening and closing incidents
+type IncidentMessenger interface {
+	Validator
+	Incident(IncidentEvent) error
+}
+
+// Group - one message of events, same condition of many nodes share one
+// section titled with number of nodes, summaries of section are indented
+func Group(events []IncidentEvent) string {
+	sections := make(map[string][]IncidentEvent)
+	titles := make([]string, 0)
+	for _, e := range events {
+		title := e.Condition
+		if Acknowledge == e.Action || Resolve == e.Action {
+			title = fmt.Sprintf("%s %s", e.Condition, Past(e.Action))
+		}
+		if _, ok := sections[title]; !ok {
+			titles = append(titles, title)
+		}
+		sections[title] = append(sections[title], e)
+	}
+
+	lines := make([]string, 0, len(events)+len(titles))
+	for _, title := range titles {
+		es := sections[title]
+		if 1 == len(es) {
+			lines = append(lines, es[0].Summary)
+			continue
+		}
+
+		lines = append(lines, fmt.Sprintf("%s on %d nodes:", title, len(es)))
+		for _, e := range es {
+			lines = append(lines, fmt.Sprintf("  %s", e.Summary))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Past - past tense of acknowledge or resolve action
+func Past(action string) string {
+	if Acknowledge == action {
+		return "acknowledged"
+	}
+	return "resolved"
+}
@@ -0,0 +1,36 @@
+package messengers
+
+// multi - send to every valid messenger
+type multi struct {
+	messengers []Messenger
+}
+
+// NewMulti - messenger sending to all valid messengers
+func NewMulti(messengers ...Messenger) Messenger {
+	return &multi{messengers: messengers}
+}
+
+// Send - send to every valid messenger, returns first error
+func (m *multi) Send(args ...interface{}) error {
+	var result error
+	for _, messenger := range m.messengers {
+		if !messenger.Valid() {
+			continue
+		}
+
+		if err := messenger.Send(args...); nil != err && nil == result {
+			result = err
+		}
+	}
+	return result
+}
+
+// Valid - any messenger is valid
+func (m *multi) Valid() bool {
+	for _, messenger := range m.messengers {
+		if messenger.Valid() {
+			return true
+		}
+	}
+	return false
+}
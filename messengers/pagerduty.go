@@ -0,0 +1,123 @@
+package messengers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/configuration"
+)
+
+const (
+	// pagerduty rejects longer summary
+	pagerDutySummaryLength = 1024
+)
+
+type pagerDutyPayload struct {
+	Summary   string `json:"summary"`
+	Source    string `json:"source"`
+	Severity  string `json:"severity"`
+	Timestamp string `json:"timestamp"`
+	Component string `json:"component"`
+	Class     string `json:"class"`
+}
+
+type pagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"`
+	DedupKey    string            `json:"dedup_key"`
+	Payload     *pagerDutyPayload `json:"payload,omitempty"`
+}
+
+type pagerDuty struct {
+	backoff    time.Duration
+	client     *http.Client
+	conditions map[string]struct{}
+	retry      int
+	routingKey string
+	severity   string
+	source     string
+	url        string
+}
+
+// NewPagerDuty - create messenger sending incidents to pagerduty events api v2
+func NewPagerDuty(config configuration.PagerDutyConfig) IncidentMessenger {
+	conditions := make(map[string]struct{})
+	for _, c := range config.Conditions {
+		conditions[c] = struct{}{}
+	}
+
+	return &pagerDuty{
+		backoff:    time.Duration(config.BackoffMillisecond) * time.Millisecond,
+		client:     &http.Client{Timeout: time.Duration(config.TimeoutSecond) * time.Second},
+		conditions: conditions,
+		retry:      config.Retry,
+		routingKey: config.RoutingKey,
+		severity:   config.Severity,
+		source:     config.Source,
+		url:        config.URL,
+	}
+}
+
+// Valid - check if url and routing key are configured
+func (p *pagerDuty) Valid() bool {
+	return "" != p.url && "" != p.routingKey
+}
+
+// Incident - trigger, acknowledge or resolve pagerduty alert of same dedup
+// key, condition not configured is ignored
+func (p *pagerDuty) Incident(e IncidentEvent) error {
+	if !p.pages(e.Condition) {
+		return nil
+	}
+
+	event := pagerDutyEvent{
+		RoutingKey:  p.routingKey,
+		EventAction: e.Action,
+		DedupKey:    e.Key,
+	}
+
+	if Trigger == e.Action {
+		summary := e.Summary
+		if pagerDutySummaryLength < len(summary) {
+			summary = summary[:pagerDutySummaryLength]
+		}
+
+		event.Payload = &pagerDutyPayload{
+			Summary:   summary,
+			Source:    p.source,
+			Severity:  p.severity,
+			Timestamp: time.Now().UTC().Format(time.RFC3339),
+			Component: e.Node,
+			Class:     e.Condition,
+		}
+	}
+
+	body, err := json.Marshal(event)
+	if nil != err {
+		return err
+	}
+
+	return withRetry(p.retry, p.backoff, func() (bool, error) {
+		return p.post(body)
+	})
+}
+
+func (p *pagerDuty) pages(condition string) bool {
+	if 0 == len(p.conditions) {
+		return true
+	}
+	_, ok := p.conditions[condition]
+	return ok
+}
+
+func (p *pagerDuty) post(body []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, p.url, bytes.NewReader(body))
+	if nil != err {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	return do(p.client, req, "pagerduty")
+}
@@ -0,0 +1,106 @@
+package messengers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/configuration"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/messengers"
+	"github.com/stretchr/testify/assert"
+)
+
+func pagerDutyConfig(url string) configuration.PagerDutyConfig {
+	return configuration.PagerDutyConfig{
+		URL:        url,
+		RoutingKey: "routing",
+		Source:     "monitor",
+		Severity:   "critical",
+		Conditions: []string{"fork", "lag"},
+		Retry:      2,
+	}
+}
+
+func TestPagerDutyValid(t *testing.T) {
+	config := pagerDutyConfig("http://localhost")
+	assert.True(t, messengers.NewPagerDuty(config).Valid(), "wrong invalid")
+
+	config.RoutingKey = ""
+	assert.False(t, messengers.NewPagerDuty(config).Valid(), "wrong valid")
+}
+
+func TestPagerDutyTrigger(t *testing.T) {
+	s := setupWebhookServer(http.StatusTooManyRequests, http.StatusAccepted)
+	defer s.Close()
+
+	p := messengers.NewPagerDuty(pagerDutyConfig(s.URL))
+	err := p.Incident(messengers.IncidentEvent{
+		Action:    messengers.Trigger,
+		Key:       "node1/fork",
+		Node:      "node1",
+		Condition: "fork",
+		Summary:   "node1 split at block 10",
+	})
+	assert.Nil(t, err, "wrong error")
+	assert.Equal(t, 2, len(s.requests), "wrong retry")
+
+	var event map[string]interface{}
+	_ = json.Unmarshal([]byte(s.requests[1].body), &event)
+	payload := event["payload"].(map[string]interface{})
+
+	assert.Equal(t, "application/json", s.requests[1].headers.Get("Content-Type"), "wrong content type")
+	assert.Equal(t, "routing", event["routing_key"], "wrong routing key")
+	assert.Equal(t, "trigger", event["event_action"], "wrong action")
+	assert.Equal(t, "node1/fork", event["dedup_key"], "wrong dedup key")
+	assert.Equal(t, "node1 split at block 10", payload["summary"], "wrong summary")
+	assert.Equal(t, "monitor", payload["source"], "wrong source")
+	assert.Equal(t, "critical", payload["severity"], "wrong severity")
+	assert.Equal(t, "node1", payload["component"], "wrong component")
+	assert.Equal(t, "fork", payload["class"], "wrong class")
+}
+
+func TestPagerDutyResolve(t *testing.T) {
+	s := setupWebhookServer()
+	defer s.Close()
+
+	p := messengers.NewPagerDuty(pagerDutyConfig(s.URL))
+	err := p.Incident(messengers.IncidentEvent{
+		Action:    messengers.Resolve,
+		Key:       "node1/lag",
+		Node:      "node1",
+		Condition: "lag",
+	})
+	assert.Nil(t, err, "wrong error")
+
+	expected := `{"routing_key":"routing","event_action":"resolve","dedup_key":"node1/lag"}`
+	assert.Equal(t, expected, s.requests[0].body, "wrong resolve event")
+}
+
+func TestPagerDutyWhenConditionNotPaged(t *testing.T) {
+	s := setupWebhookServer()
+	defer s.Close()
+
+	p := messengers.NewPagerDuty(pagerDutyConfig(s.URL))
+	err := p.Incident(messengers.IncidentEvent{
+		Action:    messengers.Trigger,
+		Key:       "node1/version",
+		Node:      "node1",
+		Condition: "version",
+	})
+	assert.Nil(t, err, "wrong error")
+	assert.Equal(t, 0, len(s.requests), "wrong request of condition not paged")
+}
+
+func TestPagerDutyWhenRejected(t *testing.T) {
+	s := setupWebhookServer(http.StatusBadRequest)
+	defer s.Close()
+
+	p := messengers.NewPagerDuty(pagerDutyConfig(s.URL))
+	err := p.Incident(messengers.IncidentEvent{
+		Action:    messengers.Acknowledge,
+		Key:       "node1/fork",
+		Condition: "fork",
+	})
+	assert.NotNil(t, err, "wrong error")
+	assert.Equal(t, 1, len(s.requests), "wrong retry of rejected event")
+}
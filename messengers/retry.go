@@ -0,0 +1,43 @@
+package messengers
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+// withRetry - call fn until it succeeds, fails without retryable or retry
+// times exhausted, backoff doubles each time
+func withRetry(retry int, backoff time.Duration, fn func() (bool, error)) error {
+	for i := 0; ; i++ {
+		retryable, err := fn()
+		if nil == err || !retryable || i >= retry {
+			return err
+		}
+
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// do - send http request, connection error, 429 and 5xx are retryable
+func do(client *http.Client, req *http.Request, name string) (bool, error) {
+	resp, err := client.Do(req)
+	if nil != err {
+		return true, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+
+	if http.StatusMultipleChoices > resp.StatusCode {
+		return false, nil
+	}
+
+	err = fmt.Errorf("%s responds %s", name, resp.Status)
+	if http.StatusTooManyRequests == resp.StatusCode || http.StatusInternalServerError <= resp.StatusCode {
+		return true, err
+	}
+	return false, err
+}
@@ -1,43 +1,239 @@
 package messengers
 
 import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/configuration"
 	"github.com/jamieabc/bitmarkd-broadcast-monitor/fault"
 	"github.com/nlopes/slack"
 )
 
+const (
+	// slack allows at most 50 blocks, title and context take 2
+	slackMaxSections = 48
+
+	// slack rejects section text longer than 3000 characters
+	slackMaxSectionLength = 3000
+)
+
+var (
+	// thread of incident without follow-up this long is forgotten, incident
+	// closed without resolve, e.g. silenced or dropped, does not post again
+	slackThreadIdle = 24 * time.Hour
+
+	severityEmoji = map[string]string{
+		Critical: ":red_circle:",
+		Warning:  ":warning:",
+		Info:     ":information_source:",
+	}
+
+	mrkdwnEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+)
+
 type slackMessenger struct {
+	sync.Mutex
 	channelID string
 	client    *slack.Client
-	rtm       *slack.RTM
+	retry     int
+
+	// threads - message which opens incident, keyed by incident key
+	threads map[string]slackThread
+}
+
+// slackThread - timestamp of message which opens incident and time of last
+// post in its thread
+type slackThread struct {
+	ts     string
+	postAt time.Time
 }
 
-// Send - send message to slack
+// Send - post message to slack channel, second argument is severity, third
+// argument is []IncidentEvent of alerts in message. Follow-ups of incident
+// posted before are replied in its thread, rest are posted in channel
 func (s *slackMessenger) Send(args ...interface{}) error {
 	if 1 > len(args) {
 		return fault.InsufficientSlackSendParameter
 	}
 
-	message := args[0].(string)
-	s.rtm.SendMessage(s.rtm.NewOutgoingMessage(message, s.channelID))
+	message, ok := args[0].(string)
+	if !ok {
+		return fault.InvalidArguments
+	}
+
+	severity := Warning
+	if 1 < len(args) {
+		if severity, ok = args[1].(string); !ok {
+			return fault.InvalidArguments
+		}
+	}
+
+	var events []IncidentEvent
+	if 2 < len(args) {
+		if events, ok = args[2].([]IncidentEvent); !ok {
+			return fault.InvalidArguments
+		}
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	now := time.Now()
+	s.prune(now)
+
+	rest := make([]IncidentEvent, 0, len(events))
+	for _, e := range events {
+		thread, ok := s.threads[e.Key]
+		if !ok || "" == e.Action {
+			rest = append(rest, e)
+			continue
+		}
+
+		if _, err := s.post(e.Summary, severity, thread.ts); nil != err {
+			return err
+		}
+		if Resolve == e.Action {
+			delete(s.threads, e.Key)
+			continue
+		}
+		thread.postAt = now
+		s.threads[e.Key] = thread
+	}
+
+	if 0 == len(rest) && 0 < len(events) {
+		return nil
+	}
+
+	// message of alerts without thread is grouped same as alert manager
+	if len(rest) != len(events) {
+		message = Group(rest)
+	}
+
+	ts, err := s.post(message, severity, "")
+	if nil != err {
+		return err
+	}
+
+	for _, e := range rest {
+		if Trigger == e.Action {
+			s.threads[e.Key] = slackThread{ts: ts, postAt: now}
+		}
+	}
 	return nil
 }
 
-// Valid - check if slack instance successful created
+// prune - forget threads idle longer than slackThreadIdle, caller holds lock
+func (s *slackMessenger) prune(now time.Time) {
+	for key, thread := range s.threads {
+		if slackThreadIdle < now.Sub(thread.postAt) {
+			delete(s.threads, key)
+		}
+	}
+}
+
+// post - post message with blocks, reply in thread if ts is not empty,
+// returns timestamp of posted message, rate limited post is retried after
+// time slack asks
+func (s *slackMessenger) post(message string, severity string, ts string) (string, error) {
+	options := []slack.MsgOption{
+		slack.MsgOptionText(message, true),
+		slack.MsgOptionBlocks(blocks(message, severity)...),
+	}
+	if "" != ts {
+		options = append(options, slack.MsgOptionTS(ts))
+	}
+
+	for i := 0; ; i++ {
+		_, posted, err := s.client.PostMessage(s.channelID, options...)
+		if nil == err {
+			return posted, nil
+		}
+
+		limited, ok := err.(*slack.RateLimitedError)
+		if !ok || i >= s.retry {
+			return "", err
+		}
+		time.Sleep(limited.RetryAfter)
+	}
+}
+
+// blocks - severity title, one section of each alert or group of nodes,
+// context of sender
+func blocks(message string, severity string) []slack.Block {
+	title := fmt.Sprintf("%s *%s*", severityEmoji[severity], strings.ToUpper(severity))
+	result := []slack.Block{
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, title, false, false), nil, nil),
+	}
+
+	sections := make([]string, 0)
+	for _, l := range strings.Split(message, "\n") {
+		trimmed := strings.TrimSpace(l)
+		switch {
+		case "" == trimmed:
+			continue
+
+		case trimmed != l && 0 < len(sections):
+			sections[len(sections)-1] += fmt.Sprintf("\n• %s", mrkdwnEscaper.Replace(trimmed))
+
+		case strings.HasSuffix(trimmed, ":"):
+			sections = append(sections, fmt.Sprintf("*%s*", mrkdwnEscaper.Replace(strings.TrimSuffix(trimmed, ":"))))
+
+		default:
+			sections = append(sections, mrkdwnEscaper.Replace(trimmed))
+		}
+	}
+
+	if slackMaxSections < len(sections) {
+		last := strings.Join(sections[slackMaxSections-1:], "\n")
+		sections = append(sections[:slackMaxSections-1], last)
+	}
+
+	for _, section := range sections {
+		result = append(result, slack.NewSectionBlock(
+			slack.NewTextBlockObject(slack.MarkdownType, truncate(section, slackMaxSectionLength), false, false),
+			nil,
+			nil,
+		))
+	}
+
+	result = append(result, slack.NewContextBlock(
+		"",
+		slack.NewTextBlockObject(slack.MarkdownType, "bitmarkd broadcast monitor", false, false),
+	))
+	return result
+}
+
+// truncate - at most length bytes of text, multi-byte character is not cut
+func truncate(text string, length int) string {
+	if length >= len(text) {
+		return text
+	}
+
+	for 0 < length && !utf8.RuneStart(text[length]) {
+		length--
+	}
+	return text[:length]
+}
+
+// Valid - check if slack token and channel are configured, errors of
+// posting are returned by Send
 func (s *slackMessenger) Valid() bool {
 	return nil != s.client
 }
 
-// NewSlack - create slack client
-func NewSlack(token string, channelID string) Messenger {
-	if "" != token && "" != channelID {
-		client := slack.New(token)
-		rtm := client.NewRTM()
-		go rtm.ManageConnection()
-		return &slackMessenger{
-			channelID: channelID,
-			client:    client,
-			rtm:       rtm,
-		}
+// NewSlack - create slack web api client
+func NewSlack(config configuration.SlackConfig) Messenger {
+	if "" == config.Token || "" == config.ChannelID {
+		return &slackMessenger{}
+	}
+
+	return &slackMessenger{
+		channelID: config.ChannelID,
+		client:    slack.New(config.Token, slack.OptionAPIURL(config.URL)),
+		retry:     config.Retry,
+		threads:   make(map[string]slackThread),
 	}
-	return &slackMessenger{}
 }
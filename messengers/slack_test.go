@@ -1,18 +1,202 @@
 package messengers_test
 
 import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
 	"testing"
+	"time"
+	"unicode/utf8"
 
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/configuration"
 	"github.com/jamieabc/bitmarkd-broadcast-monitor/messengers"
 	"github.com/stretchr/testify/assert"
 )
 
+type testSlackServer struct {
+	sync.Mutex
+	*httptest.Server
+	posts    []url.Values
+	statuses []int
+}
+
+// setupSlackServer - chat.postMessage responds statuses in order, then ok
+// with increasing timestamp, 429 asks retry immediately
+func setupSlackServer(statuses ...int) *testSlackServer {
+	s := &testSlackServer{statuses: statuses}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+
+		s.Lock()
+		defer s.Unlock()
+
+		if 0 < len(s.statuses) {
+			status := s.statuses[0]
+			s.statuses = s.statuses[1:]
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(status)
+			return
+		}
+
+		s.posts = append(s.posts, r.PostForm)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"ok": true, "channel": "%s", "ts": "%d.0"}`, r.PostForm.Get("channel"), len(s.posts))
+	}))
+	return s
+}
+
+func slackConfig(url string) configuration.SlackConfig {
+	return configuration.SlackConfig{
+		URL:       url + "/",
+		Token:     "token",
+		ChannelID: "channel",
+		Retry:     1,
+	}
+}
+
 func TestSlackValidateWhenInvalid(t *testing.T) {
-	s := messengers.NewSlack("token", "")
+	s := messengers.NewSlack(configuration.SlackConfig{Token: "token"})
 	assert.Equal(t, false, s.Valid(), "wrong validate")
 }
 
 func TestSlackValidateWhenValid(t *testing.T) {
-	s := messengers.NewSlack("token", "channel")
+	s := messengers.NewSlack(slackConfig("http://localhost"))
 	assert.Equal(t, true, s.Valid(), "wrong validate")
 }
+
+func TestSlackSend(t *testing.T) {
+	server := setupSlackServer(http.StatusTooManyRequests)
+	defer server.Close()
+
+	s := messengers.NewSlack(slackConfig(server.URL))
+	err := s.Send("lag on 2 nodes:\n  a 5 blocks behind\n  b <6> blocks behind", messengers.Critical)
+	assert.Nil(t, err, "wrong error")
+	assert.Equal(t, 1, len(server.posts), "wrong post count")
+
+	post := server.posts[0]
+	assert.Equal(t, "channel", post.Get("channel"), "wrong channel")
+	assert.Equal(t, "token", post.Get("token"), "wrong token")
+	assert.Contains(t, post.Get("text"), "a 5 blocks behind", "wrong fallback text")
+	assert.Contains(t, post.Get("blocks"), `*CRITICAL*`, "wrong title block")
+	assert.Contains(t, post.Get("blocks"), `*lag on 2 nodes*\n• a 5 blocks behind\n• b \u0026lt;6\u0026gt; blocks behind`, "wrong section block")
+	assert.Equal(t, "", post.Get("thread_ts"), "wrong thread")
+}
+
+func TestSlackSendWhenRejected(t *testing.T) {
+	server := setupSlackServer(http.StatusTooManyRequests, http.StatusTooManyRequests)
+	defer server.Close()
+
+	s := messengers.NewSlack(slackConfig(server.URL))
+	err := s.Send("a 5 blocks behind", messengers.Critical)
+	assert.NotNil(t, err, "wrong error")
+	assert.Equal(t, 0, len(server.posts), "wrong post count")
+}
+
+func TestSlackSendThreadsIncident(t *testing.T) {
+	server := setupSlackServer()
+	defer server.Close()
+
+	s := messengers.NewSlack(slackConfig(server.URL))
+
+	trigger := messengers.IncidentEvent{Action: messengers.Trigger, Key: "a/lag", Summary: "a 5 blocks behind"}
+	notify := messengers.IncidentEvent{Key: "b/version", Summary: "b version changed"}
+	err := s.Send("a 5 blocks behind\nb version changed", messengers.Critical, []messengers.IncidentEvent{trigger, notify})
+	assert.Nil(t, err, "wrong error")
+
+	repeat := messengers.IncidentEvent{Action: messengers.Trigger, Key: "a/lag", Summary: "a 7 blocks behind"}
+	other := messengers.IncidentEvent{Action: messengers.Trigger, Key: "c/lag", Summary: "c 6 blocks behind"}
+	err = s.Send("lag on 2 nodes:\n  a 7 blocks behind\n  c 6 blocks behind", messengers.Critical, []messengers.IncidentEvent{repeat, other})
+	assert.Nil(t, err, "wrong error")
+
+	resolved := messengers.IncidentEvent{Action: messengers.Resolve, Key: "a/lag", Summary: "a lag resolved after 5m0s"}
+	err = s.Send("a lag resolved after 5m0s", messengers.Info, []messengers.IncidentEvent{resolved})
+	assert.Nil(t, err, "wrong error")
+
+	err = s.Send("a 5 blocks behind", messengers.Critical, []messengers.IncidentEvent{trigger})
+	assert.Nil(t, err, "wrong error")
+
+	assert.Equal(t, 5, len(server.posts), "wrong post count")
+
+	assert.Equal(t, "", server.posts[0].Get("thread_ts"), "wrong new incident thread")
+	assert.Equal(t, "1.0", server.posts[1].Get("thread_ts"), "wrong repeat thread")
+	assert.Contains(t, server.posts[1].Get("text"), "a 7 blocks behind", "wrong repeat text")
+	assert.Equal(t, "", server.posts[2].Get("thread_ts"), "wrong other incident thread")
+	assert.Equal(t, "c 6 blocks behind", server.posts[2].Get("text"), "wrong other incident text")
+	assert.Equal(t, "1.0", server.posts[3].Get("thread_ts"), "wrong resolve thread")
+	assert.Equal(t, "", server.posts[4].Get("thread_ts"), "wrong incident after resolved")
+}
+
+func TestSlackSendGroupsEventsWithoutThread(t *testing.T) {
+	server := setupSlackServer()
+	defer server.Close()
+
+	s := messengers.NewSlack(slackConfig(server.URL))
+
+	trigger := messengers.IncidentEvent{Action: messengers.Trigger, Key: "a/lag", Condition: "lag", Summary: "a 5 blocks behind"}
+	err := s.Send("a 5 blocks behind", messengers.Critical, []messengers.IncidentEvent{trigger})
+	assert.Nil(t, err, "wrong error")
+
+	events := []messengers.IncidentEvent{
+		{Action: messengers.Trigger, Key: "a/lag", Condition: "lag", Summary: "a 7 blocks behind"},
+		{Action: messengers.Trigger, Key: "b/lag", Condition: "lag", Summary: "b 6 blocks behind"},
+		{Action: messengers.Trigger, Key: "c/lag", Condition: "lag", Summary: "c 8 blocks behind"},
+		{Key: "d/version", Condition: "version", Summary: "d version changed"},
+	}
+	message := "lag on 3 nodes:\n  a 7 blocks behind\n  b 6 blocks behind\n  c 8 blocks behind\nd version changed"
+	err = s.Send(message, messengers.Critical, events)
+	assert.Nil(t, err, "wrong error")
+
+	assert.Equal(t, 3, len(server.posts), "wrong post count")
+	assert.Equal(t, "1.0", server.posts[1].Get("thread_ts"), "wrong repeat thread")
+	assert.Equal(t, "", server.posts[2].Get("thread_ts"), "wrong grouped thread")
+	assert.Equal(t, "lag on 2 nodes:\n  b 6 blocks behind\n  c 8 blocks behind\nd version changed", server.posts[2].Get("text"), "wrong grouped text")
+}
+
+func TestSlackSendForgetsIdleThread(t *testing.T) {
+	server := setupSlackServer()
+	defer server.Close()
+
+	defer messengers.SetSlackThreadIdle(messengers.SetSlackThreadIdle(time.Millisecond))
+
+	s := messengers.NewSlack(slackConfig(server.URL))
+
+	trigger := messengers.IncidentEvent{Action: messengers.Trigger, Key: "a/lag", Summary: "a 5 blocks behind"}
+	err := s.Send("a 5 blocks behind", messengers.Critical, []messengers.IncidentEvent{trigger})
+	assert.Nil(t, err, "wrong error")
+
+	time.Sleep(10 * time.Millisecond)
+
+	repeat := messengers.IncidentEvent{Action: messengers.Trigger, Key: "a/lag", Summary: "a 7 blocks behind"}
+	err = s.Send("a 7 blocks behind", messengers.Critical, []messengers.IncidentEvent{repeat})
+	assert.Nil(t, err, "wrong error")
+
+	assert.Equal(t, 2, len(server.posts), "wrong post count")
+	assert.Equal(t, "", server.posts[1].Get("thread_ts"), "wrong thread of idle incident")
+}
+
+func TestSlackSendTruncatesSectionOnRuneBoundary(t *testing.T) {
+	server := setupSlackServer()
+	defer server.Close()
+
+	s := messengers.NewSlack(slackConfig(server.URL))
+
+	err := s.Send("a"+strings.Repeat("節", 1500), messengers.Critical)
+	assert.Nil(t, err, "wrong error")
+
+	var blocks []struct {
+		Text struct {
+			Text string `json:"text"`
+		} `json:"text"`
+	}
+	err = json.Unmarshal([]byte(server.posts[0].Get("blocks")), &blocks)
+	assert.Nil(t, err, "wrong blocks")
+
+	section := blocks[1].Text.Text
+	assert.True(t, utf8.ValidString(section), "wrong section encoding")
+	assert.NotContains(t, section, string(utf8.RuneError), "wrong section cut in character")
+	assert.Equal(t, 2998, len(section), "wrong section length")
+}
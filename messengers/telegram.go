@@ -0,0 +1,142 @@
+package messengers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/configuration"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/fault"
+)
+
+type telegramMessage struct {
+	ChatID    string `json:"chat_id"`
+	Text      string `json:"text"`
+	ParseMode string `json:"parse_mode"`
+}
+
+type telegram struct {
+	backoff time.Duration
+	chats   map[string][]string
+	client  *http.Client
+	retry   int
+	url     string
+}
+
+var markdownEscaper = strings.NewReplacer(
+	"_", "\\_",
+	"*", "\\*",
+	"`", "\\`",
+	"[", "\\[",
+)
+
+// NewTelegram - create telegram bot messenger
+func NewTelegram(config configuration.TelegramConfig) Messenger {
+	if "" == config.Token {
+		return &telegram{}
+	}
+
+	return &telegram{
+		backoff: time.Duration(config.BackoffMillisecond) * time.Millisecond,
+		chats:   config.Chats,
+		client:  &http.Client{Timeout: time.Duration(config.TimeoutSecond) * time.Second},
+		retry:   config.Retry,
+		url:     fmt.Sprintf("%s/bot%s/sendMessage", strings.TrimRight(config.URL, "/"), config.Token),
+	}
+}
+
+// Valid - check if bot token and any chat are configured
+func (t *telegram) Valid() bool {
+	if "" == t.url {
+		return false
+	}
+
+	for _, ids := range t.chats {
+		if 0 < len(ids) {
+			return true
+		}
+	}
+	return false
+}
+
+// Send - send message to chats of severity, second argument is severity,
+// warning if omitted, returns first error
+func (t *telegram) Send(args ...interface{}) error {
+	if 1 > len(args) {
+		return fault.InsufficientSendParameter
+	}
+
+	message, ok := args[0].(string)
+	if !ok {
+		return fault.InvalidArguments
+	}
+
+	severity := Warning
+	if 1 < len(args) {
+		if severity, ok = args[1].(string); !ok {
+			return fault.InvalidArguments
+		}
+	}
+
+	text := markdown(message, severity)
+
+	var result error
+	for _, id := range t.chats[severity] {
+		body, err := json.Marshal(telegramMessage{
+			ChatID:    id,
+			Text:      text,
+			ParseMode: "Markdown",
+		})
+		if nil == err {
+			err = withRetry(t.retry, t.backoff, func() (bool, error) {
+				return t.post(body)
+			})
+		}
+
+		if nil != err && nil == result {
+			result = err
+		}
+	}
+	return result
+}
+
+func (t *telegram) post(body []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, t.url, bytes.NewReader(body))
+	if nil != err {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	retryable, err := do(t.client, req, "telegram")
+
+	// url contains bot token, keep it out of logs
+	if urlErr, ok := err.(*url.Error); ok {
+		err = urlErr.Err
+	}
+	return retryable, err
+}
+
+// markdown - severity as bold title, nodes of grouped section as list,
+// telegram cannot escape inside entity so message itself is not styled
+func markdown(message string, severity string) string {
+	lines := []string{fmt.Sprintf("*%s*", strings.ToUpper(severity))}
+
+	for _, l := range strings.Split(message, "\n") {
+		trimmed := strings.TrimSpace(l)
+		switch {
+		case "" == trimmed:
+			continue
+
+		case trimmed != l:
+			lines = append(lines, fmt.Sprintf("• %s", markdownEscaper.Replace(trimmed)))
+
+		default:
+			lines = append(lines, markdownEscaper.Replace(l))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
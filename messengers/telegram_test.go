@@ -0,0 +1,76 @@
+package messengers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/configuration"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/messengers"
+	"github.com/stretchr/testify/assert"
+)
+
+func telegramConfig(url string) configuration.TelegramConfig {
+	return configuration.TelegramConfig{
+		URL:   url,
+		Token: "token",
+		Chats: map[string][]string{
+			"critical": {"-1001", "-1002"},
+			"warning":  {"-1002"},
+		},
+		Retry: 1,
+	}
+}
+
+func TestTelegramValid(t *testing.T) {
+	assert.True(t, messengers.NewTelegram(telegramConfig("http://localhost")).Valid(), "wrong invalid")
+
+	config := telegramConfig("http://localhost")
+	config.Token = ""
+	assert.False(t, messengers.NewTelegram(config).Valid(), "wrong valid without token")
+
+	config = telegramConfig("http://localhost")
+	config.Chats = nil
+	assert.False(t, messengers.NewTelegram(config).Valid(), "wrong valid without chat")
+}
+
+func TestTelegramSend(t *testing.T) {
+	s := setupWebhookServer(http.StatusTooManyRequests)
+	defer s.Close()
+
+	err := messengers.NewTelegram(telegramConfig(s.URL)).Send("lag on 2 nodes:\n  node_1 5 blocks behind\n  node2 6 blocks behind", "critical")
+	assert.Nil(t, err, "wrong error")
+	assert.Equal(t, 3, len(s.requests), "wrong request count")
+	assert.Equal(t, "/bottoken/sendMessage", s.requests[0].path, "wrong path")
+
+	var first, second map[string]string
+	_ = json.Unmarshal([]byte(s.requests[1].body), &first)
+	_ = json.Unmarshal([]byte(s.requests[2].body), &second)
+
+	assert.Equal(t, "-1001", first["chat_id"], "wrong first chat")
+	assert.Equal(t, "-1002", second["chat_id"], "wrong second chat")
+	assert.Equal(t, "Markdown", first["parse_mode"], "wrong parse mode")
+	assert.Equal(t, "*CRITICAL*\nlag on 2 nodes:\n• node\\_1 5 blocks behind\n• node2 6 blocks behind", first["text"], "wrong text")
+}
+
+func TestTelegramSendWithoutSeverity(t *testing.T) {
+	s := setupWebhookServer()
+	defer s.Close()
+
+	err := messengers.NewTelegram(telegramConfig(s.URL)).Send("message")
+	assert.Nil(t, err, "wrong error")
+	assert.Equal(t, 1, len(s.requests), "wrong warning chats")
+
+	err = messengers.NewTelegram(telegramConfig(s.URL)).Send("message", "info")
+	assert.Nil(t, err, "wrong error")
+	assert.Equal(t, 1, len(s.requests), "wrong request of severity without chat")
+}
+
+func TestTelegramSendWhenRejected(t *testing.T) {
+	s := setupWebhookServer(http.StatusBadRequest, http.StatusBadRequest)
+	defer s.Close()
+
+	err := messengers.NewTelegram(telegramConfig(s.URL)).Send("message", "critical")
+	assert.NotNil(t, err, "wrong error")
+	assert.Equal(t, 2, len(s.requests), "wrong request count")
+}
@@ -0,0 +1,118 @@
+package messengers
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"text/template"
+	"time"
+
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/configuration"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/fault"
+)
+
+// WebhookData - data of webhook body template
+type WebhookData struct {
+	Message string
+	Time    time.Time
+}
+
+type webhook struct {
+	backoff         time.Duration
+	client          *http.Client
+	headers         map[string]string
+	retry           int
+	secret          []byte
+	signatureHeader string
+	template        *template.Template
+	url             string
+}
+
+var templateFuncs = template.FuncMap{
+	// json - quoted and escaped json string of value
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+// NewWebhook - create webhook messenger posting rendered template to url
+func NewWebhook(config configuration.WebhookConfig) (Messenger, error) {
+	if "" == config.URL {
+		return &webhook{}, nil
+	}
+
+	t, err := template.New("webhook").Funcs(templateFuncs).Parse(config.Template)
+	if nil != err {
+		return nil, err
+	}
+
+	return &webhook{
+		backoff:         time.Duration(config.BackoffMillisecond) * time.Millisecond,
+		client:          &http.Client{Timeout: time.Duration(config.TimeoutSecond) * time.Second},
+		headers:         config.Headers,
+		retry:           config.Retry,
+		secret:          []byte(config.Secret),
+		signatureHeader: config.SignatureHeader,
+		template:        t,
+		url:             config.URL,
+	}, nil
+}
+
+// Valid - check if webhook url is configured
+func (w *webhook) Valid() bool {
+	return "" != w.url
+}
+
+// Send - post message to webhook, retry with doubled backoff when server
+// is not reachable, returns error or responds 429 or 5xx
+func (w *webhook) Send(args ...interface{}) error {
+	if 1 > len(args) {
+		return fault.InsufficientSendParameter
+	}
+
+	message, ok := args[0].(string)
+	if !ok {
+		return fault.InvalidArguments
+	}
+
+	var body bytes.Buffer
+	err := w.template.Execute(&body, WebhookData{
+		Message: message,
+		Time:    time.Now(),
+	})
+	if nil != err {
+		return err
+	}
+
+	return withRetry(w.retry, w.backoff, func() (bool, error) {
+		return w.post(body.Bytes())
+	})
+}
+
+func (w *webhook) post(body []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, w.url, bytes.NewReader(body))
+	if nil != err {
+		return false, err
+	}
+
+	for k, v := range w.headers {
+		req.Header.Set(k, v)
+	}
+
+	if 0 < len(w.secret) && "" != w.signatureHeader {
+		req.Header.Set(w.signatureHeader, "sha256="+signature(w.secret, body))
+	}
+
+	return do(w.client, req, "webhook")
+}
+
+// signature - hex encoded HMAC-SHA256 of body
+func signature(secret []byte, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	_, _ = mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,156 @@
+package messengers_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/configuration"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/messengers"
+	"github.com/stretchr/testify/assert"
+)
+
+type testRequest struct {
+	body    string
+	headers http.Header
+	path    string
+}
+
+type testWebhookServer struct {
+	sync.Mutex
+	*httptest.Server
+	requests []testRequest
+	statuses []int
+}
+
+// setupWebhookServer - server responds statuses in order, then 200
+func setupWebhookServer(statuses ...int) *testWebhookServer {
+	s := &testWebhookServer{statuses: statuses}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+
+		s.Lock()
+		defer s.Unlock()
+
+		s.requests = append(s.requests, testRequest{
+			body:    string(body),
+			headers: r.Header,
+			path:    r.URL.Path,
+		})
+
+		status := http.StatusOK
+		if 0 < len(s.statuses) {
+			status = s.statuses[0]
+			s.statuses = s.statuses[1:]
+		}
+		w.WriteHeader(status)
+	}))
+	return s
+}
+
+func webhookConfig(url string) configuration.WebhookConfig {
+	return configuration.WebhookConfig{
+		URL:      url,
+		Template: `{"text": {{ json .Message }}}`,
+		Headers: map[string]string{
+			"Content-Type": "application/json",
+			"X-Token":      "token",
+		},
+		Secret:             "secret",
+		SignatureHeader:    "X-Signature",
+		Retry:              2,
+		BackoffMillisecond: 1,
+		TimeoutSecond:      1,
+	}
+}
+
+func TestWebhookValidWhenNoURL(t *testing.T) {
+	w, err := messengers.NewWebhook(configuration.WebhookConfig{})
+	assert.Nil(t, err, "wrong error")
+	assert.False(t, w.Valid(), "wrong valid")
+}
+
+func TestWebhookWhenInvalidTemplate(t *testing.T) {
+	config := webhookConfig("http://localhost")
+	config.Template = "{{ .Message"
+
+	_, err := messengers.NewWebhook(config)
+	assert.NotNil(t, err, "wrong error")
+}
+
+func TestWebhookSend(t *testing.T) {
+	s := setupWebhookServer()
+	defer s.Close()
+
+	w, _ := messengers.NewWebhook(webhookConfig(s.URL))
+	assert.True(t, w.Valid(), "wrong valid")
+
+	err := w.Send("node1 \"lag\"")
+	assert.Nil(t, err, "wrong error")
+	assert.Equal(t, 1, len(s.requests), "wrong request count")
+
+	req := s.requests[0]
+	assert.Equal(t, `{"text": "node1 \"lag\""}`, req.body, "wrong body")
+	assert.Equal(t, "application/json", req.headers.Get("Content-Type"), "wrong content type")
+	assert.Equal(t, "token", req.headers.Get("X-Token"), "wrong custom header")
+
+	mac := hmac.New(sha256.New, []byte("secret"))
+	_, _ = mac.Write([]byte(req.body))
+	expected := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	assert.Equal(t, expected, req.headers.Get("X-Signature"), "wrong signature")
+}
+
+func TestWebhookSendWhenServerError(t *testing.T) {
+	s := setupWebhookServer(http.StatusInternalServerError, http.StatusTooManyRequests)
+	defer s.Close()
+
+	w, _ := messengers.NewWebhook(webhookConfig(s.URL))
+	err := w.Send("message")
+	assert.Nil(t, err, "wrong error")
+	assert.Equal(t, 3, len(s.requests), "wrong retry count")
+}
+
+func TestWebhookSendWhenRetryExhausted(t *testing.T) {
+	s := setupWebhookServer(
+		http.StatusBadGateway,
+		http.StatusBadGateway,
+		http.StatusBadGateway,
+		http.StatusBadGateway,
+	)
+	defer s.Close()
+
+	w, _ := messengers.NewWebhook(webhookConfig(s.URL))
+	err := w.Send("message")
+	assert.NotNil(t, err, "wrong error")
+	assert.Equal(t, 3, len(s.requests), "wrong request count")
+}
+
+func TestWebhookSendWhenClientError(t *testing.T) {
+	s := setupWebhookServer(http.StatusBadRequest)
+	defer s.Close()
+
+	w, _ := messengers.NewWebhook(webhookConfig(s.URL))
+	err := w.Send("message")
+	assert.NotNil(t, err, "wrong error")
+	assert.Equal(t, 1, len(s.requests), "wrong retry of client error")
+}
+
+func TestMultiSendToValidMessengers(t *testing.T) {
+	s := setupWebhookServer()
+	defer s.Close()
+
+	w, _ := messengers.NewWebhook(webhookConfig(s.URL))
+	m := messengers.NewMulti(messengers.NewSlack(configuration.SlackConfig{}), w)
+	assert.True(t, m.Valid(), "wrong valid")
+
+	err := m.Send("message")
+	assert.Nil(t, err, "wrong error")
+	assert.Equal(t, 1, len(s.requests), "wrong request count")
+
+	assert.False(t, messengers.NewMulti(messengers.NewSlack(configuration.SlackConfig{})).Valid(), "wrong valid")
+}
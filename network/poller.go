@@ -3,33 +3,54 @@ package network
 import (
 	"fmt"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/bitmark-inc/logger"
 	zmq "github.com/pebbe/zmq4"
 )
 
-// structure to hold a poll
+// structure to hold a poll shared by every registered socket
 type poller struct {
 	sync.Mutex
-	eventChan    chan zmq.Polled
-	signalPair   signalPairer
-	poll         *zmq.Poller
-	sockets      map[*zmq.Socket]zmq.State
-	shutdownChan <-chan struct{}
-	removeQueue  []Client
-	wg           sync.WaitGroup
+	signalPair signalPairer
+	poll       *zmq.Poller
+	sockets    map[*zmq.Socket]registration
+	operations []pollerOperation
+	stopped    chan struct{}
+}
+
+// client of a polled socket and where its received frames go
+type registration struct {
+	client    Client
+	frameChan chan<- [][]byte
+}
+
+// pending socket change, applied by polling goroutine, done is closed
+// when applied
+type pollerOperation struct {
+	add    bool
+	socket *zmq.Socket
+	done   chan struct{}
+	registration
 }
 
 const (
 	signalFormat = "inproc://monitor-poll-internal-signal-%d"
+	updateMsg    = "update"
+
+	// maximum messages read from one socket before serving others
+	maxDrainCount = 1000
+
+	// pause when every readable socket has full frame channel
+	congestionDelay = time.Millisecond
 )
 
 // NewPoller - create poller
-// this is just to encapsulate the zmq poll to allow removal of a socket from a socket
-func NewPoller(eventChannel chan zmq.Polled, shutdownChannel <-chan struct{}, id int) (Poller, error) {
-	var err error
-
+// this is just to encapsulate the zmq poll to allow adding and removing
+// sockets while polling, every available message of a readable socket is
+// read and sent to the frame channel registered with the socket
+func NewPoller(id int) (Poller, error) {
 	signalString := fmt.Sprintf(signalFormat, id)
 	signalPair, err := newSignalPair(signalString)
 	if nil != err {
@@ -40,68 +61,101 @@ func NewPoller(eventChannel chan zmq.Polled, shutdownChannel <-chan struct{}, id
 	poll.Add(signalPair.Receiver(), zmq.POLLIN)
 
 	return &poller{
-		eventChan:    eventChannel,
-		poll:         poll,
-		signalPair:   signalPair,
-		shutdownChan: shutdownChannel,
-		sockets:      make(map[*zmq.Socket]zmq.State),
-		removeQueue:  make([]Client, 0),
+		poll:       poll,
+		signalPair: signalPair,
+		sockets:    make(map[*zmq.Socket]registration),
+		operations: make([]pollerOperation, 0),
+		stopped:    make(chan struct{}),
 	}, nil
 }
 
-// Add - add socket to poll
-func (p *poller) Add(client Client, events zmq.State) {
-	p.wg.Wait()
+// Add - queue a client to add, received frames of client are sent to frameChan
+func (p *poller) Add(client Client, frameChan chan<- [][]byte) {
+	p.queue(pollerOperation{
+		add:    true,
+		socket: client.Socket(),
+		registration: registration{
+			client:    client,
+			frameChan: frameChan,
+		},
+	})
+}
 
-	p.Lock()
-	defer p.Unlock()
+// Remove - remove a client and wait until polling stops using its socket,
+// so client is free to close or reconnect afterwards
+func (p *poller) Remove(client Client) {
+	done := p.queue(pollerOperation{
+		add:    false,
+		socket: client.Socket(),
+	})
 
-	socket := client.Socket()
-	fmt.Printf("socket: %+v\n", socket)
+	select {
+	case <-done:
+	case <-p.stopped:
+	}
+}
 
-	// protect against duplicate add
-	if _, exist := p.sockets[socket]; exist {
-		fmt.Printf("socket %+v already exist", socket)
-		return
+// queue - returns channel closed when operation is applied
+func (p *poller) queue(op pollerOperation) <-chan struct{} {
+	op.done = make(chan struct{})
+	if nil == op.socket {
+		close(op.done)
+		return op.done
 	}
 
-	// preserve the event mask
-	p.sockets[socket] = events
+	p.Lock()
+	defer p.Unlock()
+
+	p.operations = append(p.operations, op)
 
-	// add to the internal p
-	p.poll.Add(socket, events)
+	// wake up poll to apply changes
+	_ = p.signalPair.Send(updateMsg)
+	return op.done
 }
 
-// PeriodicRemove - queue a client to remove
-func (p *poller) Remove(client Client) {
+// apply pending operations, only called from polling goroutine
+func (p *poller) update() {
 	p.Lock()
-	p.removeQueue = append(p.removeQueue, client)
-	p.wg.Add(1)
-	p.Unlock()
-}
+	defer p.Unlock()
 
-func (p *poller) remove() {
-	if 0 == len(p.removeQueue) {
-		return
+	for _, op := range p.operations {
+		_, exist := p.sockets[op.socket]
+
+		// protect against duplicate add or remove
+		switch {
+		case op.add && !exist:
+			p.sockets[op.socket] = op.registration
+			p.poll.Add(op.socket, zmq.POLLIN)
+
+		case !op.add && exist:
+			p.removeSocket(op.socket)
+		}
+		close(op.done)
 	}
+	p.operations = p.operations[:0]
+}
 
+// removeSocket - caller holds lock
+func (p *poller) removeSocket(socket *zmq.Socket) {
+	delete(p.sockets, socket)
+	_ = p.poll.RemoveBySocket(socket)
+}
+
+// prune - remove sockets in error, e.g. closed without remove, returns
+// count of removed sockets, only called from polling goroutine
+func (p *poller) prune() int {
 	p.Lock()
-	for _, c := range p.removeQueue {
-		socket := c.Socket()
+	defer p.Unlock()
 
-		// protect against duplicate remove
-		if _, ok := p.sockets[socket]; !ok {
-			p.wg.Done()
-			continue
+	count := 0
+	for socket, r := range p.sockets {
+		if _, err := socket.GetEvents(); nil != err {
+			logger.Criticalf("socket of %s with error: %s, remove from poller", r.client, err)
+			p.removeSocket(socket)
+			count++
 		}
-
-		// remove the socket
-		delete(p.sockets, socket)
-		_ = p.poll.RemoveBySocket(socket)
-		p.wg.Done()
 	}
-	p.removeQueue = p.removeQueue[:0]
-	p.Unlock()
+	return count
 }
 
 // Start - polling event
@@ -113,33 +167,87 @@ func (p *poller) Start(args []interface{}) {
 	timeout := args[0].(time.Duration)
 	shutdown := args[1].(<-chan struct{})
 	go waitShutdownEvent(p, shutdown)
+	defer close(p.stopped)
 
 loop:
 	for {
-		p.Lock()
-		poll := p.poll
-		p.Unlock()
-
-		polled, err := poll.Poll(timeout)
+		polled, err := p.poll.Poll(timeout)
 		if nil != err {
-			logger.Criticalf("poll with error: %s\n", err)
-			break loop
+			if zmq.AsErrno(err) == zmq.ETERM {
+				logger.Criticalf("poll with error: %s", err)
+				break loop
+			}
+
+			// one bad socket must not stop polling of other nodes
+			if 0 == p.prune() {
+				logger.Criticalf("poll with error: %s", err)
+				<-time.After(congestionDelay)
+			}
+			continue
 		}
 
+		congested := 0 < len(polled)
 		for _, zmqEvent := range polled {
 			if p.signalPair.Receiver() == zmqEvent.Socket {
-				break loop
+				msg, err := zmqEvent.Socket.Recv(0)
+				if nil != err || stopMsg == msg {
+					break loop
+				}
+				p.update()
+				congested = false
+				continue
 			}
 
-			// de-duplicate polled events
-			p.eventChan <- zmqEvent
-			<-time.After(1 * time.Second)
+			if !p.drain(zmqEvent.Socket) {
+				congested = false
+			}
+		}
+
+		// messages stay queued in zmq until receivers catch up
+		if congested {
+			<-time.After(congestionDelay)
 		}
 	}
-	p.remove()
+}
+
+// drain - read available messages of a socket without blocking, returns
+// true when stopped by a full frame channel
+func (p *poller) drain(socket *zmq.Socket) bool {
+	p.Lock()
+	r, ok := p.sockets[socket]
+	p.Unlock()
+
+	if !ok {
+		return false
+	}
+
+	for i := 0; i < maxDrainCount; i++ {
+		// only poller sends to the channel, so free space cannot disappear
+		if len(r.frameChan) == cap(r.frameChan) {
+			return true
+		}
+
+		// client may already hold a new socket after reconnect, read the
+		// polled one
+		data, err := socket.RecvMessageBytes(zmq.DONTWAIT)
+		if nil != err {
+			if zmq.AsErrno(err) != zmq.Errno(syscall.EAGAIN) {
+				logger.Criticalf("receive from %s with error: %s, remove from poller", r.client, err)
+				p.Lock()
+				p.removeSocket(socket)
+				p.Unlock()
+			}
+			return false
+		}
+
+		r.frameChan <- data
+	}
+	return false
 }
 
 func (p *poller) stop() {
+	p.Lock()
+	defer p.Unlock()
 	p.signalPair.Stop()
 }
 
@@ -150,7 +258,7 @@ func waitShutdownEvent(p *poller, shutdown <-chan struct{}) {
 
 // Poller - poll interface
 type Poller interface {
-	Add(client Client, events zmq.State)
+	Add(client Client, frameChan chan<- [][]byte)
 	Remove(client Client)
 	Start(args []interface{})
 }
@@ -0,0 +1,258 @@
+package network
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	zmq "github.com/pebbe/zmq4"
+)
+
+const (
+	testChain        = "testing"
+	testCategory     = "transfer"
+	testTimeout      = 10 * time.Second
+	testFrameChanLen = 1000
+	slowJoinerDelay  = 100 * time.Millisecond
+)
+
+var testPollerID = 1000
+
+// testClient - plain inproc SUB socket satisfying Client
+type testClient struct {
+	sync.Mutex
+	address string
+	socket  *zmq.Socket
+}
+
+func (t *testClient) Close() error                              { return t.socket.Close() }
+func (t *testClient) Connect(*Connection, []byte, string) error { return nil }
+func (t *testClient) ConnectedTo() *connected                   { return nil }
+func (t *testClient) IsConnected() bool                         { return true }
+func (t *testClient) IsConnectedTo([]byte) bool                 { return true }
+func (t *testClient) Send(...interface{}) error                 { return nil }
+func (t *testClient) Socket() *zmq.Socket                       { return t.socket }
+func (t *testClient) String() string                            { return "test client" }
+func (t *testClient) Receive(flags zmq.Flag) ([][]byte, error) {
+	t.Lock()
+	defer t.Unlock()
+	return t.socket.RecvMessageBytes(flags)
+}
+
+// Reconnect - close socket and subscribe again with a new one
+func (t *testClient) Reconnect() error {
+	t.Lock()
+	defer t.Unlock()
+
+	if err := t.socket.Close(); nil != err {
+		return err
+	}
+
+	socket, err := newSubscriber(t.address)
+	if nil != err {
+		return err
+	}
+	t.socket = socket
+	return nil
+}
+
+func setupPublisher(tb testing.TB, address string) *zmq.Socket {
+	pub, err := zmq.NewSocket(zmq.PUB)
+	if nil != err {
+		tb.Fatalf("new publisher with error: %s", err)
+	}
+	_ = pub.SetSndhwm(0)
+	if err = pub.Bind(address); nil != err {
+		tb.Fatalf("bind publisher with error: %s", err)
+	}
+	return pub
+}
+
+func newSubscriber(address string) (*zmq.Socket, error) {
+	sub, err := zmq.NewSocket(zmq.SUB)
+	if nil != err {
+		return nil, err
+	}
+	_ = sub.SetRcvhwm(0)
+	_ = sub.SetSubscribe(testChain)
+	if err = sub.Connect(address); nil != err {
+		sub.Close()
+		return nil, err
+	}
+	return sub, nil
+}
+
+func setupSubscriber(tb testing.TB, address string) *testClient {
+	sub, err := newSubscriber(address)
+	if nil != err {
+		tb.Fatalf("new subscriber with error: %s", err)
+	}
+	return &testClient{address: address, socket: sub}
+}
+
+func setupPoller(tb testing.TB) (Poller, chan struct{}) {
+	testPollerID++
+	p, err := NewPoller(testPollerID)
+	if nil != err {
+		tb.Fatalf("new poller with error: %s", err)
+	}
+	shutdown := make(chan struct{})
+	go p.Start([]interface{}{time.Second, (<-chan struct{})(shutdown)})
+	return p, shutdown
+}
+
+func publish(pub *zmq.Socket, count int) {
+	for i := 0; i < count; i++ {
+		_, _ = pub.SendMessage(testChain, testCategory, fmt.Sprintf("%d", i))
+	}
+}
+
+func receive(tb testing.TB, frameChan <-chan [][]byte, count int) {
+	timeout := time.After(testTimeout)
+	for i := 0; i < count; i++ {
+		select {
+		case data := <-frameChan:
+			if 3 != len(data) {
+				tb.Fatalf("wrong frame parts %d", len(data))
+			}
+		case <-timeout:
+			tb.Fatalf("only receive %d of %d messages", i, count)
+		}
+	}
+}
+
+func TestPollerDrainsEveryMessage(t *testing.T) {
+	address := "inproc://poller-test-drain"
+	pub := setupPublisher(t, address)
+	defer pub.Close()
+	sub := setupSubscriber(t, address)
+	defer sub.Close()
+
+	p, shutdown := setupPoller(t)
+	defer close(shutdown)
+
+	frameChan := make(chan [][]byte, testFrameChanLen)
+	p.Add(sub, frameChan)
+	time.Sleep(slowJoinerDelay)
+
+	count := 5 * testFrameChanLen
+	start := time.Now()
+	go publish(pub, count)
+	receive(t, frameChan, count)
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("receive %d messages takes %s", count, elapsed)
+	}
+}
+
+func TestPollerDispatchToOwner(t *testing.T) {
+	p, shutdown := setupPoller(t)
+	defer close(shutdown)
+
+	var pubs []*zmq.Socket
+	var chans []chan [][]byte
+	for i := 0; i < 3; i++ {
+		address := fmt.Sprintf("inproc://poller-test-owner-%d", i)
+		pub := setupPublisher(t, address)
+		defer pub.Close()
+		sub := setupSubscriber(t, address)
+		defer sub.Close()
+
+		frameChan := make(chan [][]byte, testFrameChanLen)
+		p.Add(sub, frameChan)
+		pubs = append(pubs, pub)
+		chans = append(chans, frameChan)
+	}
+	time.Sleep(slowJoinerDelay)
+
+	for i, pub := range pubs {
+		publish(pub, i+1)
+	}
+
+	for i, frameChan := range chans {
+		receive(t, frameChan, i+1)
+		if 0 != len(frameChan) {
+			t.Errorf("socket %d receives %d unexpected messages", i, len(frameChan))
+		}
+	}
+}
+
+func TestPollerRemoveAndAddAgain(t *testing.T) {
+	address := "inproc://poller-test-reconnect"
+	pub := setupPublisher(t, address)
+	defer pub.Close()
+	sub := setupSubscriber(t, address)
+	defer sub.Close()
+
+	p, shutdown := setupPoller(t)
+	defer close(shutdown)
+
+	frameChan := make(chan [][]byte, testFrameChanLen)
+	p.Add(sub, frameChan)
+	time.Sleep(slowJoinerDelay)
+
+	publish(pub, 10)
+	receive(t, frameChan, 10)
+
+	// socket is closed right after remove returns
+	p.Remove(sub)
+	if err := sub.Reconnect(); nil != err {
+		t.Fatalf("reconnect with error: %s", err)
+	}
+	p.Add(sub, frameChan)
+	time.Sleep(slowJoinerDelay)
+
+	publish(pub, 10)
+	receive(t, frameChan, 10)
+}
+
+func TestPollerKeepPollingWhenSocketClosed(t *testing.T) {
+	p, shutdown := setupPoller(t)
+	defer close(shutdown)
+
+	closedAddress := "inproc://poller-test-closed"
+	closedPub := setupPublisher(t, closedAddress)
+	defer closedPub.Close()
+	closed := setupSubscriber(t, closedAddress)
+	p.Add(closed, make(chan [][]byte, testFrameChanLen))
+
+	address := "inproc://poller-test-alive"
+	pub := setupPublisher(t, address)
+	defer pub.Close()
+	sub := setupSubscriber(t, address)
+	defer sub.Close()
+
+	frameChan := make(chan [][]byte, testFrameChanLen)
+	p.Add(sub, frameChan)
+	time.Sleep(slowJoinerDelay)
+
+	// closed without remove, poller drops it instead of stop polling
+	_ = closed.Close()
+	publish(closedPub, 1)
+	publish(pub, 10)
+	receive(t, frameChan, 10)
+}
+
+func BenchmarkPollerThroughput(b *testing.B) {
+	address := "inproc://poller-benchmark"
+	pub := setupPublisher(b, address)
+	defer pub.Close()
+	sub := setupSubscriber(b, address)
+	defer sub.Close()
+
+	p, shutdown := setupPoller(b)
+	defer close(shutdown)
+
+	frameChan := make(chan [][]byte, testFrameChanLen)
+	p.Add(sub, frameChan)
+	time.Sleep(slowJoinerDelay)
+
+	b.ResetTimer()
+	start := time.Now()
+	go publish(pub, b.N)
+	receive(b, frameChan, b.N)
+	b.StopTimer()
+
+	b.ReportMetric(float64(b.N)/time.Since(start).Seconds(), "msgs/s")
+}
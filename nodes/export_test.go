@@ -0,0 +1,26 @@
+package nodes
+
+import (
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/bot"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/nodes/node"
+)
+
+// Querier - querier of monitored or replayed nodes
+func Querier(n Nodes) bot.Querier {
+	return node.NewQuerier(nodesOf(n))
+}
+
+// Check - summarize recorders of monitored nodes now instead of waiting
+// checker loop
+func Check(n Nodes) {
+	for _, nd := range nodesOf(n) {
+		nd.Check()
+	}
+}
+
+func nodesOf(n Nodes) []node.Node {
+	if r, ok := n.(*replay); ok {
+		return r.nodeArr
+	}
+	return n.(*nodes).nodeArr
+}
@@ -0,0 +1,86 @@
+package node
+
+import (
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/alert"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/messengers"
+)
+
+// conditions of alert, incident is keyed by node name and condition, node
+// back is message of resolved node silent
+const (
+	forkCondition         = "fork"
+	lagCondition          = "lag"
+	nodeBackCondition     = "node-back"
+	nodeSilentCondition   = "node-silent"
+	notNormalCondition    = "not-normal"
+	unconfirmedCondition  = "unconfirmed"
+	versionCondition      = "version"
+	versionDriftCondition = "version-drift"
+)
+
+// conditions - built in conditions, rule name must not take one of them
+var conditions = map[string]struct{}{
+	forkCondition:         {},
+	lagCondition:          {},
+	nodeBackCondition:     {},
+	nodeSilentCondition:   {},
+	notNormalCondition:    {},
+	unconfirmedCondition:  {},
+	versionCondition:      {},
+	versionDriftCondition: {},
+}
+
+// criticalConditions - conditions stopping node from serving chain, others
+// are warning
+var criticalConditions = map[string]struct{}{
+	forkCondition:       {},
+	lagCondition:        {},
+	nodeSilentCondition: {},
+	notNormalCondition:  {},
+}
+
+func severity(condition string) string {
+	if _, ok := criticalConditions[condition]; ok {
+		return messengers.Critical
+	}
+	return messengers.Warning
+}
+
+// fire - condition of node happens, repeats are suppressed by alert manager
+func fire(name string, condition string, msg string) {
+	fireOnChain(name, chainOf(name), condition, msg)
+}
+
+// fireOnChain - condition of pair of nodes on chain happens
+func fireOnChain(name string, chain string, condition string, msg string) {
+	alerts.Fire(alert.Alert{
+		Node:      name,
+		Condition: condition,
+		Message:   msg,
+		Severity:  severity(condition),
+		Chain:     chain,
+		Tags:      tagsOf(name),
+	})
+}
+
+// notify - one-off event of node
+func notify(name string, condition string, msg string) {
+	alerts.Notify(alert.Alert{
+		Node:      name,
+		Condition: condition,
+		Message:   msg,
+		Severity:  severity(condition),
+		Chain:     chainOf(name),
+		Tags:      tagsOf(name),
+	})
+}
+
+// resolve - condition of node clears
+func resolve(name string, condition string) {
+	alerts.Resolve(name, condition)
+}
+
+// resolveWith - condition of node clears, message tells how it cleared
+func resolveWith(name string, condition string, msg string) {
+	alerts.ResolveWith(name, condition, msg)
+}
@@ -5,14 +5,21 @@ import (
 	"time"
 
 	"github.com/jamieabc/bitmarkd-broadcast-monitor/db"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/digest"
 
 	"github.com/jamieabc/bitmarkd-broadcast-monitor/recorder"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/rule"
 )
 
 const (
-	transactionCheckMinute = 2 * time.Minute
-	blockCheckMinute       = 2 * time.Minute
-	measurement            = "transaction-droprate"
+	transactionCheckMinute  = 2 * time.Minute
+	blockCheckMinute        = 2 * time.Minute
+	confirmationCheckMinute = 2 * time.Minute
+	measurement             = "transaction-droprate"
+	confirmationMeasurement = "transaction-confirmation"
+	commandCheckMinute      = 2 * time.Minute
+	heartbeatCheckMinute    = 2 * time.Minute
+	commandMeasurement      = "command"
 )
 
 // checkerLoop - loop to check all summaries
@@ -26,6 +33,10 @@ func checkerLoop(args []interface{}) {
 	log := n.Log()
 	transactionTimer := time.NewTimer(transactionCheckMinute)
 	blockTimer := time.NewTimer(blockCheckMinute)
+	confirmationTimer := time.NewTimer(confirmationCheckMinute)
+	commandTimer := time.NewTimer(commandCheckMinute)
+	disconnectTimer := time.NewTimer(disconnectCheckMinute)
+	heartbeatTimer := time.NewTimer(heartbeatCheckMinute)
 
 	for {
 		select {
@@ -34,24 +45,174 @@ func checkerLoop(args []interface{}) {
 			return
 
 		case <-transactionTimer.C:
-			ts := rs.transaction.Summary().(*recorder.TransactionSummary)
-
-			writeToInfluxDB(ts, n.Name())
-
-			log.Infof("transaction summary: %s", ts)
+			checkTransaction(n, rs)
 			transactionTimer.Reset(transactionCheckMinute)
 
 		case <-blockTimer.C:
-			bs := rs.block.Summary().(*recorder.BlocksSummary)
-			if !bs.Valid() {
-				sendToSlack(n.Name(), bs.String())
-			}
-			log.Infof("block summary: %s", bs)
+			checkBlock(n, rs)
 			blockTimer.Reset(blockCheckMinute)
+
+		case <-confirmationTimer.C:
+			checkConfirmation(n, rs)
+			confirmationTimer.Reset(confirmationCheckMinute)
+
+		case <-commandTimer.C:
+			checkCommand(n, rs)
+			commandTimer.Reset(commandCheckMinute)
+
+		case <-disconnectTimer.C:
+			checkDisconnect(n)
+			disconnectTimer.Reset(disconnectCheckMinute)
+
+		case <-heartbeatTimer.C:
+			checkHeartbeat(n, rs)
+			heartbeatTimer.Reset(heartbeatCheckMinute)
 		}
 	}
 }
 
+func checkTransaction(n Node, rs recorders) {
+	if !n.Expect(transferCmdStr) {
+		return
+	}
+
+	ts := rs.transaction.Summary().(*recorder.TransactionSummary)
+	keepSummary(n.Name(), transactionSummary, ts)
+
+	writeToInfluxDB(ts, n.Name())
+
+	n.Log().Infof("transaction summary: %s", ts)
+	digestGauge(n.Name(), digest.TransactionDropRate, ts.Droprate)
+
+	// node not in normal mode is still syncing, drop rate is meaningless
+	if !isNormal(n.Name()) {
+		n.Log().Info("node not in normal mode, suppress drop rate alert")
+		reset(n.Name(), rule.DropRate)
+		return
+	}
+
+	evaluate(n.Name(), rule.DropRate, ts.Droprate)
+}
+
+func checkBlock(n Node, rs recorders) {
+	if !n.Expect(blockCmdStr) {
+		return
+	}
+
+	bs := rs.block.Summary().(*recorder.BlocksSummary)
+	keepSummary(n.Name(), blockSummary, bs)
+	n.Log().Infof("block summary: %s", bs)
+	digestBlocks(n.Name(), bs)
+
+	evaluate(n.Name(), rule.MissingBlocks, float64(len(bs.MissingBlocks)))
+	evaluate(n.Name(), rule.ForkDepth, float64(bs.MaxForkDepth()))
+	evaluate(n.Name(), rule.ConfirmTime, bs.MaxConfirm.Minutes())
+}
+
+// checkDisconnect - minutes since last broadcast received, node never
+// received any broadcast counts from start of monitor
+func checkDisconnect(n Node) {
+	received := lastReceivedOf(n.Name())
+	if received.IsZero() {
+		return
+	}
+
+	minutes := currentTime().Sub(received).Minutes()
+	digestGauge(n.Name(), digest.DisconnectTime, minutes)
+	evaluate(n.Name(), rule.DisconnectTime, minutes)
+}
+
+func checkHeartbeat(n Node, rs recorders) {
+	if !n.Expect(heartbeatCmdStr) {
+		return
+	}
+
+	hs := rs.heartbeat.Summary().(*recorder.HeartbeatSummary)
+	keepSummary(n.Name(), heartbeatSummary, hs)
+	digestGauge(n.Name(), digest.HeartbeatDropRate, hs.Droprate)
+	n.Log().Infof("heartbeat summary: %s", hs)
+
+	evaluate(n.Name(), rule.HeartbeatDropRate, hs.Droprate)
+}
+
+// checkConfirmation - blocks are fetched from command port, nothing to match
+// without it
+func checkConfirmation(n Node, rs recorders) {
+	if !n.Expect(transferCmdStr) || nil == n.Remote() || nil == n.CommandSender() {
+		return
+	}
+
+	cs := rs.confirmation.Summary().(*recorder.ConfirmationSummary)
+	keepSummary(n.Name(), confirmationSummary, cs)
+	writeConfirmationToInfluxDB(cs, n.Name())
+
+	// each unconfirmed transaction is reported once by recorder
+	if !cs.Valid() {
+		notify(n.Name(), unconfirmedCondition, render(n.Name(), unconfirmedCondition, cs))
+	}
+	n.Log().Infof("confirmation summary: %s", cs)
+}
+
+// points of same tags and time overwrite each other, latencies are written
+// as average and maximum of each check
+func writeConfirmationToInfluxDB(sum *recorder.ConfirmationSummary, name string) {
+	if 0 == len(sum.Confirmed) {
+		return
+	}
+
+	db.Add(db.InfluxData{
+		Fields: map[string]interface{}{
+			"value": sum.AverageLatency().Seconds(),
+			"max":   sum.MaxLatency().Seconds(),
+			"count": len(sum.Confirmed),
+		},
+		Measurement: confirmationMeasurement,
+		Tags:        map[string]string{"name": name},
+		Timing:      time.Now(),
+	})
+}
+
+// checkCommand - command port health, independent of broadcast health
+func checkCommand(n Node, rs recorders) {
+	if nil == n.Remote() || nil == n.CommandSender() {
+		return
+	}
+
+	cs := rs.command.Summary().(*recorder.CommandSummary)
+	keepSummary(n.Name(), commandSummary, cs)
+	writeCommandToInfluxDB(cs, n.Name())
+
+	if !cs.Valid() {
+		n.Log().Warnf("command port availability low: %s", cs)
+		return
+	}
+	n.Log().Infof("command summary: %s", cs)
+}
+
+func writeCommandToInfluxDB(sum *recorder.CommandSummary, name string) {
+	now := time.Now()
+	for _, s := range sum.Stats {
+		db.Add(db.InfluxData{
+			Fields: map[string]interface{}{
+				"success":      s.Success,
+				"not-found":    s.NotFound,
+				"failure":      s.Failure,
+				"timeout":      s.Timeout,
+				"cancelled":    s.Cancelled,
+				"availability": s.Availability(),
+				"latency":      s.AverageLatency().Seconds(),
+				"max-latency":  s.MaxLatency.Seconds(),
+			},
+			Measurement: commandMeasurement,
+			Tags: map[string]string{
+				"name":    name,
+				"command": s.Command,
+			},
+			Timing: now,
+		})
+	}
+}
+
 func writeToInfluxDB(sum *recorder.TransactionSummary, name string) {
 	db.Add(db.InfluxData{
 		Fields:      map[string]interface{}{"value": sum.Droprate},
@@ -0,0 +1,31 @@
+package node
+
+import (
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/digest"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/recorder"
+)
+
+// digestGauge - sample of metric of node kept for periodic digest
+func digestGauge(name string, metric string, value float64) {
+	if nil == digests {
+		return
+	}
+	digests.Gauge(name, metric, value)
+}
+
+// digestBlocks - block drop rate, forks and longest confirm of block summary
+func digestBlocks(name string, bs *recorder.BlocksSummary) {
+	if nil == digests {
+		return
+	}
+
+	missing := len(bs.MissingBlocks)
+	if expected := int(bs.BlockCount) + missing; 0 < expected {
+		digests.Gauge(name, digest.BlockDropRate, float64(missing)/float64(expected))
+	}
+
+	for _, f := range bs.Forks {
+		digests.Fork(name, f.Begin, f.End)
+	}
+	digests.Gauge(name, digest.ConfirmTime, bs.MaxConfirm.Minutes())
+}
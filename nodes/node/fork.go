@@ -0,0 +1,186 @@
+package node
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/bitmark-inc/bitmarkd/blockdigest"
+	"github.com/bitmark-inc/bitmarkd/genesis"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/communication"
+)
+
+// digester - get block digest of height, implemented by Remote
+type digester interface {
+	BlockDigest(context.Context, uint64) (*communication.BlockDigestResponse, error)
+}
+
+// tip - latest block reported by node command port
+type tip struct {
+	height uint64
+	digest blockdigest.Digest
+}
+
+type forkMember struct {
+	chain string
+	node  Node
+	tip   tip
+}
+
+// forkResult - common ancestor of two nodes
+type forkResult struct {
+	common  uint64
+	forked  bool
+	heightA uint64
+	heightB uint64
+}
+
+var (
+	forkMutex   sync.Mutex
+	forkMembers map[string]*forkMember
+)
+
+func initialiseForkLocator() {
+	forkMutex.Lock()
+	defer forkMutex.Unlock()
+
+	forkMembers = make(map[string]*forkMember)
+}
+
+// registerForkMember - node with command port joins fork locating
+func registerForkMember(n Node, chain string) {
+	forkMutex.Lock()
+	defer forkMutex.Unlock()
+
+	forkMembers[n.Name()] = &forkMember{
+		chain: chain,
+		node:  n,
+	}
+}
+
+// updateTip - record latest tip of node, returns other nodes of same chain
+// with known tips
+func updateTip(n Node, t tip) []forkMember {
+	forkMutex.Lock()
+	defer forkMutex.Unlock()
+
+	self, ok := forkMembers[n.Name()]
+	if !ok {
+		return nil
+	}
+	self.tip = t
+
+	others := make([]forkMember, 0, len(forkMembers))
+	for name, m := range forkMembers {
+		if name == n.Name() || m.chain != self.chain || 0 == m.tip.height {
+			continue
+		}
+		others = append(others, *m)
+	}
+	return others
+}
+
+// checkForks - compare tip of node with other nodes, fork of a pair of nodes
+// is one incident whichever node finds it
+func checkForks(n Node, t tip) {
+	log := n.Log()
+
+	for _, other := range updateTip(n, t) {
+		key := forkKey(n.Name(), other.node.Name())
+		if t == other.tip {
+			resolve(key, forkCondition)
+			continue
+		}
+
+		result, err := locateFork(ctx, n.Remote(), other.node.Remote(), t.height, other.tip.height)
+		if nil != err {
+			log.Errorf("locate fork with %s error: %s", other.node.Name(), err)
+			continue
+		}
+
+		if !result.forked {
+			resolve(key, forkCondition)
+			continue
+		}
+
+		msg := forkMessage(n.Name(), other.node.Name(), result)
+		log.Warn(msg)
+		fireOnChain(key, other.chain, forkCondition, msg)
+	}
+}
+
+// locateFork - binary search highest block with same digest on both nodes
+// genesis block is always common
+func locateFork(ctx context.Context, a digester, b digester, heightA uint64, heightB uint64) (forkResult, error) {
+	result := forkResult{
+		heightA: heightA,
+		heightB: heightB,
+	}
+
+	top := heightA
+	if heightB < top {
+		top = heightB
+	}
+
+	same, err := sameDigest(ctx, a, b, top)
+	if nil != err {
+		return result, err
+	}
+	if same {
+		result.common = top
+		return result, nil
+	}
+
+	low, high := genesis.BlockNumber, top
+	for high-low > 1 {
+		mid := low + (high-low)/2
+		same, err = sameDigest(ctx, a, b, mid)
+		if nil != err {
+			return result, err
+		}
+
+		if same {
+			low = mid
+		} else {
+			high = mid
+		}
+	}
+
+	result.common = low
+	result.forked = true
+	return result, nil
+}
+
+func sameDigest(ctx context.Context, a digester, b digester, height uint64) (bool, error) {
+	digestA, err := a.BlockDigest(ctx, height)
+	if nil != err {
+		return false, err
+	}
+
+	digestB, err := b.BlockDigest(ctx, height)
+	if nil != err {
+		return false, err
+	}
+
+	return digestA.Digest == digestB.Digest, nil
+}
+
+func forkKey(nameA string, nameB string) string {
+	if nameA > nameB {
+		nameA, nameB = nameB, nameA
+	}
+	return fmt.Sprintf("%s-%s", nameA, nameB)
+}
+
+func forkMessage(nameA string, nameB string, result forkResult) string {
+	return render(forkKey(nameA, nameB), forkCondition, forkSummary{
+		Split:   result.common + 1,
+		Common:  result.common,
+		NodeA:   nameA,
+		DepthA:  result.heightA - result.common,
+		HeightA: result.heightA,
+		NodeB:   nameB,
+		DepthB:  result.heightB - result.common,
+		HeightB: result.heightB,
+	})
+}
@@ -0,0 +1,83 @@
+package node
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/bitmark-inc/bitmarkd/blockdigest"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/communication"
+	"github.com/stretchr/testify/assert"
+)
+
+// chainDigester - blocks up to fork are common, later blocks differ by branch
+type chainDigester struct {
+	branch byte
+	fork   uint64
+	height uint64
+	calls  int
+}
+
+func (c *chainDigester) BlockDigest(_ context.Context, height uint64) (*communication.BlockDigestResponse, error) {
+	c.calls++
+	if height > c.height {
+		return nil, fmt.Errorf("block not found")
+	}
+
+	var d blockdigest.Digest
+	d[0] = byte(height)
+	d[1] = byte(height >> 8)
+	if height > c.fork {
+		d[2] = c.branch
+	}
+	return &communication.BlockDigestResponse{Digest: d}, nil
+}
+
+func TestLocateForkWhenSameChain(t *testing.T) {
+	a := &chainDigester{branch: 1, fork: 1000, height: 100}
+	b := &chainDigester{branch: 2, fork: 1000, height: 105}
+
+	result, err := locateFork(context.Background(), a, b, 100, 105)
+
+	assert.Nil(t, err, "wrong error")
+	assert.False(t, result.forked, "wrong fork")
+	assert.Equal(t, uint64(100), result.common, "wrong common block")
+}
+
+func TestLocateForkWhenForked(t *testing.T) {
+	a := &chainDigester{branch: 1, fork: 345, height: 350}
+	b := &chainDigester{branch: 2, fork: 345, height: 360}
+
+	result, err := locateFork(context.Background(), a, b, 350, 360)
+
+	assert.Nil(t, err, "wrong error")
+	assert.True(t, result.forked, "wrong fork")
+	assert.Equal(t, uint64(345), result.common, "wrong common block")
+	assert.True(t, a.calls < 20, "wrong search steps")
+	assert.Contains(t, forkMessage("a", "b", result), "split at block 346", "wrong split")
+	assert.Contains(t, forkMessage("a", "b", result), "a is 5 blocks deep", "wrong depth")
+	assert.Contains(t, forkMessage("a", "b", result), "b is 15 blocks deep", "wrong depth")
+}
+
+func TestLocateForkWhenForkedAfterGenesis(t *testing.T) {
+	a := &chainDigester{branch: 1, fork: 1, height: 10}
+	b := &chainDigester{branch: 2, fork: 1, height: 10}
+
+	result, _ := locateFork(context.Background(), a, b, 10, 10)
+
+	assert.True(t, result.forked, "wrong fork")
+	assert.Equal(t, uint64(1), result.common, "wrong common block")
+}
+
+func TestLocateForkWhenError(t *testing.T) {
+	a := &chainDigester{branch: 1, fork: 5, height: 10}
+	b := &chainDigester{branch: 2, fork: 5, height: 3}
+
+	_, err := locateFork(context.Background(), a, b, 10, 10)
+
+	assert.NotNil(t, err, "wrong error")
+}
+
+func TestForkKeyIsSymmetric(t *testing.T) {
+	assert.Equal(t, forkKey("a", "b"), forkKey("b", "a"), "wrong key")
+}
@@ -0,0 +1,173 @@
+package node
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/db"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/digest"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/rule"
+)
+
+const (
+	heightMeasurement = "node-height"
+)
+
+// lagState - since when node is behind more than threshold
+type lagState struct {
+	since time.Time
+}
+
+// lag - blocks behind best height of fleet and latest block broadcast by
+// node itself
+type lag struct {
+	height          uint64
+	fleetHeight     uint64
+	broadcastHeight uint64
+}
+
+func (l lag) fleet() uint64 {
+	return behind(l.height, l.fleetHeight)
+}
+
+func (l lag) broadcast() uint64 {
+	return behind(l.height, l.broadcastHeight)
+}
+
+// blocks - larger lag of both
+func (l lag) blocks() uint64 {
+	if l.fleet() > l.broadcast() {
+		return l.fleet()
+	}
+	return l.broadcast()
+}
+
+func behind(height uint64, best uint64) uint64 {
+	if height >= best {
+		return 0
+	}
+	return best - height
+}
+
+func heightLoop(args []interface{}) {
+	if 2 != len(args) {
+		fmt.Println("heightLoop wrong argument length")
+		return
+	}
+	n := args[0].(Node)
+	chain := args[1].(string)
+	log := n.Log()
+	interval := time.Duration(heightConfig.IntervalSecond) * time.Second
+	timer := time.NewTimer(interval)
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Infof("terminate height loop")
+			return
+
+		case <-timer.C:
+			timer.Reset(interval)
+
+			height, err := remoteHeight(n)
+			if nil != err {
+				log.Errorf("get remote height error: %s", err)
+				continue
+			}
+
+			checkHeight(n, chain, height, time.Now())
+		}
+	}
+}
+
+// checkHeight - write lag of node and alert node lagging behind threshold
+// longer than lag duration
+func checkHeight(n Node, chain string, height uint64, now time.Time) {
+	l := updateHeight(n.Name(), chain, height)
+	writeHeightToInfluxDB(l, n.Name())
+	n.Log().Debugf("height %d, fleet lag %d, broadcast lag %d", height, l.fleet(), l.broadcast())
+	digestGauge(n.Name(), digest.Lag, float64(l.blocks()))
+	evaluate(n.Name(), rule.Lag, float64(l.blocks()))
+	if ruled(n.Name(), rule.Lag) {
+		return
+	}
+
+	lasting, ok := lagging(n.Name(), l, now)
+	if !ok {
+		resolve(n.Name(), lagCondition)
+		return
+	}
+
+	msg := render(n.Name(), lagCondition, lagSummary{
+		Blocks:          l.blocks(),
+		Lasting:         lasting,
+		Height:          l.height,
+		BestHeight:      l.fleetHeight,
+		BroadcastHeight: l.broadcastHeight,
+	})
+	n.Log().Warn(msg)
+	fire(n.Name(), lagCondition, msg)
+}
+
+// updateHeight - record height of node, returns lag against best height of
+// same chain and block broadcast by node
+func updateHeight(name string, chain string, height uint64) lag {
+	statusMutex.Lock()
+	defer statusMutex.Unlock()
+
+	s := statusOf(name)
+	s.chain = chain
+	s.height = height
+
+	l := lag{height: height, broadcastHeight: s.broadcastHeight}
+	for _, other := range statuses {
+		if other.chain == chain && other.height > l.fleetHeight {
+			l.fleetHeight = other.height
+		}
+	}
+	return l
+}
+
+// updateBroadcastHeight - record latest block number broadcast by node,
+// lower number after reorg or chain reset replaces it
+func updateBroadcastHeight(name string, chain string, number uint64) {
+	statusMutex.Lock()
+	defer statusMutex.Unlock()
+
+	s := statusOf(name)
+	s.chain = chain
+	s.broadcastHeight = number
+}
+
+// lagging - true with lasting duration when node is behind more than
+// threshold for at least lag duration
+func lagging(name string, l lag, now time.Time) (time.Duration, bool) {
+	statusMutex.Lock()
+	defer statusMutex.Unlock()
+
+	s := statusOf(name)
+	if l.blocks() <= uint64(heightConfig.LagThreshold) {
+		s.lag = lagState{}
+		return time.Duration(0), false
+	}
+
+	if s.lag.since.IsZero() {
+		s.lag.since = now
+	}
+
+	lasting := now.Sub(s.lag.since)
+	return lasting, lasting >= time.Duration(heightConfig.LagMinute)*time.Minute
+}
+
+func writeHeightToInfluxDB(l lag, name string) {
+	db.Add(db.InfluxData{
+		Fields: map[string]interface{}{
+			"height":        int64(l.height),
+			"fleet-lag":     int64(l.fleet()),
+			"broadcast-lag": int64(l.broadcast()),
+		},
+		Measurement: heightMeasurement,
+		Tags:        map[string]string{"name": name},
+		Timing:      time.Now(),
+	})
+}
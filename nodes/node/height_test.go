@@ -0,0 +1,100 @@
+package node
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/bitmark-inc/logger"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/alert"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/configuration"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/rule"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupHeightTest() {
+	initialiseStatus()
+	heightConfig = configuration.HeightConfig{
+		IntervalSecond: 60,
+		LagThreshold:   3,
+		LagMinute:      5,
+	}
+}
+
+func TestUpdateHeight(t *testing.T) {
+	setupHeightTest()
+
+	updateBroadcastHeight("a", "testing", 110)
+	updateHeight("a", "testing", 108)
+	updateHeight("c", "bitmark", 500)
+
+	l := updateHeight("b", "testing", 100)
+	assert.Equal(t, uint64(8), l.fleet(), "wrong fleet lag")
+	assert.Equal(t, uint64(0), l.broadcast(), "wrong broadcast lag of other node")
+
+	updateBroadcastHeight("b", "testing", 104)
+	l = updateHeight("b", "testing", 100)
+	assert.Equal(t, uint64(4), l.broadcast(), "wrong broadcast lag")
+	assert.Equal(t, uint64(8), l.blocks(), "wrong lag")
+
+	l = updateHeight("a", "testing", 111)
+	assert.Equal(t, uint64(0), l.blocks(), "wrong lag of best node")
+}
+
+func TestUpdateBroadcastHeightAfterReset(t *testing.T) {
+	setupHeightTest()
+
+	updateBroadcastHeight("a", "testing", 110)
+	updateBroadcastHeight("a", "testing", 5)
+
+	l := updateHeight("a", "testing", 5)
+	assert.Equal(t, uint64(0), l.broadcast(), "wrong broadcast lag after reset")
+}
+
+func TestLagging(t *testing.T) {
+	setupHeightTest()
+	now := time.Now()
+	behind := lag{height: 100, fleetHeight: 110}
+
+	_, ok := lagging("a", behind, now)
+	assert.False(t, ok, "wrong lagging before lag duration")
+
+	_, ok = lagging("a", lag{height: 108, fleetHeight: 110}, now.Add(time.Minute))
+	assert.False(t, ok, "wrong lagging within threshold")
+
+	_, ok = lagging("a", behind, now.Add(2*time.Minute))
+	assert.False(t, ok, "wrong lagging after reset")
+
+	lasting, ok := lagging("a", behind, now.Add(7*time.Minute))
+	assert.True(t, ok, "wrong lagging")
+	assert.Equal(t, 5*time.Minute, lasting, "wrong lagging duration")
+}
+
+func TestCheckHeightWithLagRule(t *testing.T) {
+	dir := setupWatchdogLogger(t)
+	defer os.RemoveAll(dir)
+	defer logger.Finalise()
+
+	setupHeightTest()
+	alerts = alert.NewManager(nil, nil, time.Hour, time.Second)
+	rules, _ = rule.New([]configuration.RuleConfig{
+		{Name: "behind", Metric: rule.Lag, Comparison: ">", Threshold: 3, Nodes: []string{"a"}},
+	})
+	defer func() { rules = nil }()
+
+	a := &node{name: "a", log: logger.New("a")}
+	b := &node{name: "b", log: logger.New("b")}
+	now := time.Now()
+	updateHeight("c", "testing", 110)
+	for _, at := range []time.Time{now, now.Add(6 * time.Minute)} {
+		checkHeight(a, "testing", 100, at)
+		checkHeight(b, "testing", 100, at)
+	}
+
+	conditions := make(map[string][]string)
+	for _, i := range alerts.Incidents() {
+		conditions[i.Node] = append(conditions[i.Node], i.Condition)
+	}
+	assert.Equal(t, []string{"behind"}, conditions["a"], "wrong incidents of node with lag rule")
+	assert.Equal(t, []string{lagCondition}, conditions["b"], "wrong incidents of node without lag rule")
+}
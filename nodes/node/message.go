@@ -0,0 +1,121 @@
+package node
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/fault"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/message"
+)
+
+// template of rules without template of own name
+const ruleTemplate = "rule"
+
+// defaultTemplates - built in message of each alert condition, summary of
+// template is summary of recorder, info of node or one of summaries below
+var defaultTemplates = map[string]string{
+	forkCondition:         "split at block {{ .Summary.Split }}, last common block {{ .Summary.Common }}, {{ .Summary.NodeA }} is {{ .Summary.DepthA }} blocks deep at {{ .Summary.HeightA }}, {{ .Summary.NodeB }} is {{ .Summary.DepthB }} blocks deep at {{ .Summary.HeightB }}",
+	lagCondition:          "{{ .Summary.Blocks }} blocks behind for {{ duration .Summary.Lasting }}, height {{ .Summary.Height }}, best height {{ .Summary.BestHeight }}, broadcast block {{ .Summary.BroadcastHeight }}",
+	nodeBackCondition:     "heartbeat back after {{ duration .Summary.Duration }} of silence",
+	nodeSilentCondition:   "no heartbeat for {{ duration .Summary.Duration }}",
+	notNormalCondition:    "not in normal mode at height {{ .Summary.Height }}",
+	unconfirmedCondition:  "{{ len .Summary.Unconfirmed }} transactions not confirmed after {{ duration .Summary.Timeout }}: {{ range $i, $u := .Summary.Unconfirmed }}{{ if $i }}, {{ end }}{{ $u.ID }} ({{ duration $u.Age }}){{ end }}",
+	versionCondition:      "{{ .Summary.Field }} changed from {{ .Summary.From }} to {{ .Summary.To }}",
+	versionDriftCondition: "version drift: {{ range $i, $g := .Summary }}{{ if $i }}, {{ end }}{{ $g.Version }} ({{ join $g.Nodes }}){{ end }}",
+	ruleTemplate:          "{{ .Summary.Message }}",
+}
+
+// templates - default templates until overridden by config
+var templates = defaults()
+
+func defaults() message.Templates {
+	t, err := message.New(defaultTemplates, nil)
+	if nil != err {
+		panic(err)
+	}
+	return t
+}
+
+// lagSummary - lag of node lasting longer than lag duration
+type lagSummary struct {
+	Blocks          uint64
+	Lasting         time.Duration
+	Height          uint64
+	BestHeight      uint64
+	BroadcastHeight uint64
+}
+
+// forkSummary - fork of pair of nodes
+type forkSummary struct {
+	Split   uint64
+	Common  uint64
+	NodeA   string
+	DepthA  uint64
+	HeightA uint64
+	NodeB   string
+	DepthB  uint64
+	HeightB uint64
+}
+
+// infoChange - version or chain change of node
+type infoChange struct {
+	Field string
+	From  string
+	To    string
+}
+
+// versionGroup - nodes running same version
+type versionGroup struct {
+	Version string
+	Nodes   []string
+}
+
+// initialiseTemplates - overrides of config are keyed by alert condition,
+// rule template or rule name
+func initialiseTemplates(overrides map[string]string, ruleNames []string) error {
+	known := make(map[string]struct{})
+	for key := range defaultTemplates {
+		known[key] = struct{}{}
+	}
+	for _, name := range ruleNames {
+		known[name] = struct{}{}
+	}
+
+	for key := range overrides {
+		if _, ok := known[key]; !ok {
+			fmt.Printf("template %q with error: %s\n", key, fault.UnknownTemplate)
+			return fault.UnknownTemplate
+		}
+	}
+
+	t, err := message.New(defaultTemplates, overrides)
+	if nil != err {
+		return err
+	}
+	templates = t
+	return nil
+}
+
+// render - message of condition of node, rule without template of own
+// name is rendered by rule template, summary is printed when no template
+// renders, caller must not hold statusMutex
+func render(name string, condition string, summary interface{}) string {
+	key := condition
+	if !templates.Has(key) {
+		key = ruleTemplate
+	}
+
+	config := configOf(name)
+	msg, err := templates.Render(key, message.Data{
+		Node:      name,
+		Condition: condition,
+		Config:    config,
+		Tags:      config.Tags,
+		Summary:   summary,
+	})
+	if nil != err {
+		fmt.Printf("render %s of %s with error: %s\n", condition, name, err)
+		return fmt.Sprint(summary)
+	}
+	return msg
+}
@@ -0,0 +1,60 @@
+package node
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/configuration"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/fault"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/recorder"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/rule"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderDefaultTemplates(t *testing.T) {
+	initialiseStatus()
+	templates = defaults()
+
+	msg := render("a", lagCondition, lagSummary{
+		Blocks:          3,
+		Lasting:         5*time.Minute + time.Millisecond,
+		Height:          100,
+		BestHeight:      103,
+		BroadcastHeight: 102,
+	})
+	assert.Equal(t, "3 blocks behind for 5m0s, height 100, best height 103, broadcast block 102", msg, "wrong lag message")
+
+	msg = render("a", unconfirmedCondition, &recorder.ConfirmationSummary{
+		Timeout: time.Hour,
+		Unconfirmed: []recorder.Unconfirmed{
+			{ID: "t1", Age: time.Hour + time.Millisecond},
+			{ID: "t2", Age: 2 * time.Hour},
+		},
+	})
+	assert.Equal(t, "2 transactions not confirmed after 1h0m0s: t1 (1h0m0s), t2 (2h0m0s)", msg, "wrong unconfirmed message")
+
+	msg = render("a", "drop", rule.Result{Message: "drop-rate 0.25 > 0.1"})
+	assert.Equal(t, "drop-rate 0.25 > 0.1", msg, "wrong rule message")
+}
+
+func TestRenderOverrideTemplates(t *testing.T) {
+	initialiseStatus()
+	defer func() { templates = defaults() }()
+
+	registerNode(configuration.NodeConfig{Name: "a", IP: "127.0.0.1", Tags: []string{"mainnet"}})
+	err := initialiseTemplates(map[string]string{
+		lagCondition: "{{ .Config.IP }} {{ join .Tags }} lags {{ .Summary.Blocks }}",
+		"drop":       "{{ .Node }} drops {{ percent .Summary.Value }}",
+	}, []string{"drop"})
+	assert.Nil(t, err, "wrong error")
+
+	assert.Equal(t, "127.0.0.1 mainnet lags 3", render("a", lagCondition, lagSummary{Blocks: 3}), "wrong lag message")
+	assert.Equal(t, "a drops 25%", render("a", "drop", rule.Result{Value: 0.25}), "wrong rule message")
+}
+
+func TestInitialiseTemplatesWithUnknownKey(t *testing.T) {
+	defer func() { templates = defaults() }()
+
+	err := initialiseTemplates(map[string]string{"drop": "{{ .Node }}"}, nil)
+	assert.Equal(t, fault.UnknownTemplate, err, "wrong error")
+}
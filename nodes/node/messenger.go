@@ -0,0 +1,159 @@
+package node
+
+import (
+	"fmt"
+
+	"github.com/bitmark-inc/logger"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/alert"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/configuration"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/fault"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/messengers"
+)
+
+// types of messenger, messenger of top level config section is named by
+// its type
+const (
+	slackType     = "slack"
+	webhookType   = "webhook"
+	emailType     = "email"
+	telegramType  = "telegram"
+	pagerDutyType = "pagerduty"
+)
+
+// newMessengers - messengers of top level config sections and named
+// messenger instances, keyed by name
+func newMessengers(configs configuration.Configuration) (map[string]messengers.Messenger, map[string]messengers.IncidentMessenger, error) {
+	webhook, err := messengers.NewWebhook(configs.WebhookConfig())
+	if nil != err {
+		return nil, nil, err
+	}
+
+	senders := map[string]messengers.Messenger{
+		emailType:    messengers.NewEmail(configs.EmailConfig()),
+		slackType:    messengers.NewSlack(configs.SlackConfig()),
+		telegramType: messengers.NewTelegram(configs.TelegramConfig()),
+		webhookType:  webhook,
+	}
+	paging := map[string]messengers.IncidentMessenger{
+		pagerDutyType: messengers.NewPagerDuty(configs.PagerDutyConfig()),
+	}
+
+	for _, c := range configs.MessengersConfig() {
+		_, isSender := senders[c.Name]
+		_, isPaging := paging[c.Name]
+		if "" == c.Name || isSender || isPaging {
+			fmt.Printf("messenger %q with error: %s\n", c.Name, fault.InvalidMessengerName)
+			return nil, nil, fault.InvalidMessengerName
+		}
+
+		switch c.Type {
+		case slackType:
+			senders[c.Name] = messengers.NewSlack(c.Slack)
+
+		case webhookType:
+			if senders[c.Name], err = messengers.NewWebhook(c.Webhook); nil != err {
+				fmt.Printf("messenger %q with error: %s\n", c.Name, err)
+				return nil, nil, err
+			}
+
+		case emailType:
+			senders[c.Name] = messengers.NewEmail(c.Email)
+
+		case telegramType:
+			senders[c.Name] = messengers.NewTelegram(c.Telegram)
+
+		case pagerDutyType:
+			paging[c.Name] = messengers.NewPagerDuty(c.PagerDuty)
+
+		default:
+			fmt.Printf("messenger %q with error: %s\n", c.Name, fault.InvalidMessengerType)
+			return nil, nil, fault.InvalidMessengerType
+		}
+	}
+	return senders, paging, nil
+}
+
+// newRoutes - routes of config, messengers of route must exist
+func newRoutes(
+	configs []configuration.RouteConfig,
+	senders map[string]messengers.Messenger,
+	paging map[string]messengers.IncidentMessenger,
+) ([]alert.Route, error) {
+	routes := make([]alert.Route, 0, len(configs))
+	for i, c := range configs {
+		r, err := alert.NewRoute(c)
+		if nil != err {
+			fmt.Printf("route %d with error: %s\n", i, err)
+			return nil, err
+		}
+
+		for _, name := range c.Messengers {
+			if !exist(name, senders, paging) {
+				fmt.Printf("route %d targets unknown messenger %q\n", i, name)
+				return nil, fault.InvalidRouteMessenger
+			}
+		}
+		routes = append(routes, r)
+	}
+	return routes, nil
+}
+
+func exist(name string, senders map[string]messengers.Messenger, paging map[string]messengers.IncidentMessenger) bool {
+	_, isSender := senders[name]
+	_, isPaging := paging[name]
+	return isSender || isPaging
+}
+
+// recording - messenger of replay, alert is written to log instead of sent,
+// valid as messenger it replaces so routes behave same as live
+type recording struct {
+	name  string
+	valid bool
+	log   *logger.L
+}
+
+// recordingMessengers - recording messengers named same as messengers
+func recordingMessengers(
+	senders map[string]messengers.Messenger,
+	paging map[string]messengers.IncidentMessenger,
+) (map[string]messengers.Messenger, map[string]messengers.IncidentMessenger) {
+	log := logger.New("replay-alert")
+
+	recordingSenders := make(map[string]messengers.Messenger)
+	for name, m := range senders {
+		recordingSenders[name] = &recording{name: name, valid: m.Valid(), log: log}
+	}
+
+	recordingPaging := make(map[string]messengers.IncidentMessenger)
+	for name, m := range paging {
+		recordingPaging[name] = &recording{name: name, valid: m.Valid(), log: log}
+	}
+	return recordingSenders, recordingPaging
+}
+
+// Valid - valid as replaced messenger
+func (r *recording) Valid() bool {
+	return r.valid
+}
+
+// Send - first argument is message, optional second one is severity
+func (r *recording) Send(args ...interface{}) error {
+	if 0 == len(args) {
+		return nil
+	}
+
+	severity := messengers.Warning
+	if 1 < len(args) {
+		if s, ok := args[1].(string); ok {
+			severity = s
+		}
+	}
+	r.log.Warnf("%s %s: %v", r.name, severity, args[0])
+	return nil
+}
+
+// Incident - change of incident
+func (r *recording) Incident(e messengers.IncidentEvent) error {
+	r.log.Warnf("%s %s %s: %s", r.name, e.Action, e.Key, e.Summary)
+	return nil
+}
@@ -0,0 +1,33 @@
+package node
+
+import (
+	"testing"
+
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/configuration"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/fault"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/messengers"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRoutes(t *testing.T) {
+	senders := map[string]messengers.Messenger{
+		"mainnet": messengers.NewSlack(configuration.SlackConfig{}),
+	}
+	paging := map[string]messengers.IncidentMessenger{
+		"oncall": messengers.NewPagerDuty(configuration.PagerDutyConfig{}),
+	}
+
+	routes, err := newRoutes([]configuration.RouteConfig{
+		{Messengers: []string{"mainnet", "oncall"}, Chains: []string{"bitmark"}},
+	}, senders, paging)
+	assert.Nil(t, err, "wrong error")
+	assert.Equal(t, 1, len(routes), "wrong routes")
+
+	_, err = newRoutes([]configuration.RouteConfig{
+		{Messengers: []string{"testnet"}},
+	}, senders, paging)
+	assert.Equal(t, fault.InvalidRouteMessenger, err, "wrong error of unknown messenger")
+
+	_, err = newRoutes([]configuration.RouteConfig{{}}, senders, paging)
+	assert.Equal(t, fault.InvalidRoute, err, "wrong error of route without messenger")
+}
@@ -5,45 +5,62 @@ import (
 	"context"
 	"encoding/hex"
 	"fmt"
+	"time"
+
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/alert"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/capture"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/clock"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/digest"
 
 	"github.com/jamieabc/bitmarkd-broadcast-monitor/tasks"
 
 	"github.com/jamieabc/bitmarkd-broadcast-monitor/cache"
-	"github.com/jamieabc/bitmarkd-broadcast-monitor/messengers"
 
 	"github.com/jamieabc/bitmarkd-broadcast-monitor/recorder"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/rule"
 
 	"github.com/bitmark-inc/logger"
 	"github.com/jamieabc/bitmarkd-broadcast-monitor/configuration"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/messengers"
 	"github.com/jamieabc/bitmarkd-broadcast-monitor/network"
 )
 
 // Node - node interface
 type Node interface {
 	BroadcastReceiver() network.Client
+	Check()
 	Close() error
 	CommandSender() network.Client
+	Expect(string) bool
 	Log() *logger.L
 	Monitor([]interface{})
 	Name() string
+	Process([][]byte, time.Time)
 	Remote() Remote
 }
 
 type recorders struct {
-	heartbeat   recorder.Recorder
-	transaction recorder.Recorder
-	block       recorder.Recorder
+	heartbeat    recorder.Recorder
+	transaction  recorder.Recorder
+	block        recorder.Recorder
+	confirmation recorder.Recorder
+	command      recorder.Recorder
+	watchdog     *watchdog
 }
 
 type node struct {
-	blockRecorder       recorder.Recorder
-	config              configuration.NodeConfig
-	heartbeatRecorder   recorder.Recorder
-	id                  int
-	log                 *logger.L
-	name                string
-	remote              Remote
-	transactionRecorder recorder.Recorder
+	blockRecorder        recorder.Recorder
+	commandRecorder      recorder.Recorder
+	config               configuration.NodeConfig
+	confirmationRecorder recorder.Recorder
+	heartbeatRecorder    recorder.Recorder
+	id                   int
+	log                  *logger.L
+	name                 string
+	remote               Remote
+	subscription         subscription
+	transactionRecorder  recorder.Recorder
+	watchdog             *watchdog
 }
 
 type nodeKeys struct {
@@ -54,41 +71,182 @@ type nodeKeys struct {
 
 var (
 	heartbeatIntervalSecond int
+	heartbeatSilenceBeats   int
+	confirmTimeoutMinute    int
+	heightConfig            configuration.HeightConfig
 	keys                    configuration.Keys
-	slack                   messengers.Messenger
+	alerts                  alert.Manager
+	digests                 digest.Digest
+	rules                   rule.Rules
 	caches                  cache.Cache
 	task                    tasks.Tasks
 	ctx                     context.Context
+	poller                  network.Poller
+	capturer                capture.Writer
+
+	// replaced by replay clock when replaying captured broadcasts
+	currentTime = time.Now
+)
+
+const (
+	pollerID = 0
 )
 
 // Initialise - setup node related common variables
-func Initialise(configs configuration.Configuration, t tasks.Tasks, context context.Context) {
+// one poller is shared by broadcast receivers of all nodes
+func Initialise(configs configuration.Configuration, t tasks.Tasks, context context.Context) error {
+	senders, err := initialise(configs, t, context, false)
+	if nil != err {
+		return err
+	}
+	task.Go(alerts.Loop, ctx.Done())
+
+	digests, err = digest.New(configs.DigestConfig(), senders)
+	if nil != err {
+		return err
+	}
+	task.Go(digests.Loop, ctx.Done())
+
+	poller, err = network.NewPoller(pollerID)
+	if nil != err {
+		return err
+	}
+	task.Go(poller.Start, pollerTimeoutSecond, ctx.Done())
+
+	return nil
+}
+
+// InitialiseReplay - setup node related common variables without network,
+// alerts go to recording messengers which write them to log, and are sent
+// by FlushAlerts, alerts and rules follow replay clock, digest is off so
+// replay never touches live digest state
+func InitialiseReplay(configs configuration.Configuration, t tasks.Tasks, context context.Context, now func() time.Time) error {
+	if _, err := initialise(configs, t, context, true); nil != err {
+		return err
+	}
+
+	currentTime = now
+	alerts.SetTimeSource(now)
+	digests = nil
+	return nil
+}
+
+// FlushAlerts - send pending alerts now, replay flushes on its own clock
+func FlushAlerts() {
+	alerts.Flush()
+}
+
+// initialise - common variables of live monitor and replay, returns
+// messengers keyed by name
+func initialise(configs configuration.Configuration, t tasks.Tasks, context context.Context, replay bool) (map[string]messengers.Messenger, error) {
 	heartbeatIntervalSecond = configs.HeartbeatIntervalInSecond()
+	heartbeatSilenceBeats = configs.HeartbeatSilenceInBeats()
+	confirmTimeoutMinute = configs.ConfirmTimeoutInMinute()
+	heightConfig = configs.HeightConfig()
 	keys = configs.Key()
 	task = t
 	ctx = context
 
-	slackConfig := configs.SlackConfig()
-	slack = messengers.NewSlack(slackConfig.Token, slackConfig.ChannelID)
-	var err error
+	senders, paging, err := newMessengers(configs)
+	if nil != err {
+		return nil, err
+	}
+
+	rules, err = newRules(configs.RulesConfig(), senders, paging)
+	if nil != err {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(configs.RulesConfig()))
+	for _, r := range configs.RulesConfig() {
+		names = append(names, r.Name)
+	}
+	if err = initialiseTemplates(configs.TemplatesConfig(), names); nil != err {
+		return nil, err
+	}
+
+	routes, err := newRoutes(configs.RoutesConfig(), senders, paging)
+	if nil != err {
+		return nil, err
+	}
+
+	if replay {
+		senders, paging = recordingMessengers(senders, paging)
+	}
+
+	alertConfig := configs.AlertConfig()
+	alerts = alert.NewManager(
+		senders,
+		paging,
+		time.Duration(alertConfig.CooldownMinute)*time.Minute,
+		time.Duration(alertConfig.GroupSecond)*time.Second,
+	)
+	alerts.SetLog(logger.New("alert"))
+	windows := make([]alert.Window, 0, len(configs.SilencesConfig()))
+	for _, c := range configs.SilencesConfig() {
+		w, err := alert.NewWindow(c)
+		if nil != err {
+			fmt.Printf("silence %q with error: %s\n", c.Name, err)
+			return nil, err
+		}
+		windows = append(windows, w)
+	}
+	alerts.Schedule(windows)
+	alerts.Routes(routes)
+
 	caches, err = cache.NewCache()
 	if nil != err {
 		fmt.Printf("new cache with error: %s\n", err)
 	}
+
+	initialiseForkLocator()
+	initialiseStatus()
+
+	return senders, nil
+}
+
+// InitialiseCapture - record every received broadcast into capture files
+func InitialiseCapture(config configuration.CaptureConfig) error {
+	var err error
+	capturer, err = capture.NewWriter(config)
+	return err
+}
+
+// Finalise - release node related common resources
+func Finalise() {
+	if nil == capturer {
+		return
+	}
+
+	if err := capturer.Close(); nil != err {
+		fmt.Printf("close capture with error: %s\n", err)
+	}
 }
 
 // NewNode - create new node
 func NewNode(config configuration.NodeConfig, idx int) (intf Node, err error) {
 	log := logger.New(config.Name)
 
+	s, err := newSubscription(config.Subscribe)
+	if nil != err {
+		log.Errorf("subscribe %v with error: %s", config.Subscribe, err)
+		return nil, err
+	}
+
 	n := &node{
-		blockRecorder:       recorder.NewBlock(),
-		config:              config,
-		heartbeatRecorder:   recorder.NewHeartbeat(float64(heartbeatIntervalSecond), task, ctx),
-		id:                  idx,
-		log:                 log,
-		name:                config.Name,
-		transactionRecorder: recorder.NewTransaction(),
+		blockRecorder:        recorder.NewBlock(),
+		commandRecorder:      recorder.NewCommand(),
+		config:               config,
+		confirmationRecorder: recorder.NewConfirmation(time.Duration(confirmTimeoutMinute) * time.Minute),
+		heartbeatRecorder:    recorder.NewHeartbeat(float64(heartbeatIntervalSecond), task, ctx),
+		id:                   idx,
+		log:                  log,
+		name:                 config.Name,
+		subscription:         s,
+		transactionRecorder:  recorder.NewTransaction(),
+	}
+	if s.accept(heartbeatCmdStr) {
+		n.watchdog = newWatchdog(heartbeatIntervalSecond, heartbeatSilenceBeats)
 	}
 
 	nodeKey, err := parseKeys(keys, config.PublicKey)
@@ -99,15 +257,46 @@ func NewNode(config configuration.NodeConfig, idx int) (intf Node, err error) {
 
 	log.Debugf("public key: %q, private key: %q, remote public key: %q", nodeKey.public, nodeKey.private, nodeKey.remotePublic)
 
-	n.remote, err = newClient(config, nodeKey)
+	n.remote, err = newClient(config, nodeKey, n.commandRecorder)
 	if nil != err {
 		return nil, err
 	}
+	if "" != config.CommandPort {
+		registerForkMember(n, config.Chain)
+	}
+	registerNode(config)
 	log.Infof("new node: %s", n.Name())
 
 	return n, nil
 }
 
+// NewReplayNode - create node without connection, fed by replay of captured broadcasts
+func NewReplayNode(config configuration.NodeConfig, idx int) (Node, error) {
+	log := logger.New(config.Name)
+
+	s, err := newSubscription(config.Subscribe)
+	if nil != err {
+		log.Errorf("subscribe %v with error: %s", config.Subscribe, err)
+		return nil, err
+	}
+
+	log.Infof("new replay node: %s", config.Name)
+	registerNode(config)
+
+	return &node{
+		blockRecorder:        recorder.NewBlock(),
+		commandRecorder:      recorder.NewCommand(),
+		config:               config,
+		confirmationRecorder: recorder.NewConfirmation(time.Duration(confirmTimeoutMinute) * time.Minute),
+		heartbeatRecorder:    recorder.NewHeartbeat(float64(heartbeatIntervalSecond), task, ctx),
+		id:                   idx,
+		log:                  log,
+		name:                 config.Name,
+		subscription:         s,
+		transactionRecorder:  recorder.NewTransaction(),
+	}, nil
+}
+
 func parseKeys(keys configuration.Keys, remotePublicKeyStr string) (*nodeKeys, error) {
 	publicKey, err := network.ReadPublicKey(keys.Public)
 	if nil != err {
@@ -135,18 +324,6 @@ func parseKeys(keys configuration.Keys, remotePublicKeyStr string) (*nodeKeys, e
 	}, nil
 }
 
-func sendToSlack(node string, msg string) {
-	if slack.Valid() {
-		finalMsg := fmt.Sprintf("%s %s", node, msg)
-		err := slack.Send(finalMsg)
-		if nil != err {
-			fmt.Printf("send slack message %s with error: %s\n", msg, err)
-		}
-	} else {
-		fmt.Printf("invalid slack instance\n")
-	}
-}
-
 // BroadcastReceiverClient - get zmq broadcast receiver remote
 func (n *node) BroadcastReceiver() network.Client {
 	return n.remote.BroadcastReceiver()
@@ -164,12 +341,21 @@ func (n *node) Remote() Remote {
 
 // Close - close connection
 func (n *node) Close() error {
+	if nil == n.remote {
+		return nil
+	}
+
 	if err := n.remote.Close(); nil != err {
 		return err
 	}
 	return nil
 }
 
+// Expect - true if node subscribes broadcast category
+func (n *node) Expect(category string) bool {
+	return n.subscription.accept(category)
+}
+
 // Log - get logger
 func (n *node) Log() *logger.L {
 	return n.log
@@ -177,18 +363,36 @@ func (n *node) Log() *logger.L {
 
 // Monitor - start to monitor
 func (n *node) Monitor(args []interface{}) {
-	rs := recorders{
-		heartbeat:   n.heartbeatRecorder,
-		transaction: n.transactionRecorder,
-		block:       n.blockRecorder,
-	}
+	rs := n.recorders()
+	timer := clock.NewClock()
 
 	n.log.Info("start to monitor")
-	task.Go(receiverLoop, n, rs, n.id)
+
+	// replay node is fed and checked by replay, expired records are
+	// removed by check on replay clock
+	if nil == n.remote {
+		<-ctx.Done()
+		n.Log().Info("stop")
+		return
+	}
+
+	task.Go(rs.transaction.PeriodicRemove, timer, ctx.Done())
+	task.Go(rs.block.PeriodicRemove, timer, ctx.Done())
+	task.Go(rs.confirmation.PeriodicRemove, timer, ctx.Done())
+	task.Go(rs.command.PeriodicRemove, timer, ctx.Done())
+
+	// disconnect time counts from start of monitor until first broadcast
+	received(n.Name(), time.Now())
+
+	task.Go(receiverLoop, n, rs)
 	task.Go(checkerLoop, n, rs)
+	if nil != n.watchdog {
+		task.Go(watchdogLoop, n, n.watchdog)
+	}
 
 	if n.config.CommandPort != "" {
-		task.Go(senderLoop, n)
+		task.Go(senderLoop, n, rs)
+		task.Go(heightLoop, n, n.config.Chain)
 	}
 
 	<-ctx.Done()
@@ -197,6 +401,38 @@ func (n *node) Monitor(args []interface{}) {
 	return
 }
 
+// Check - remove expired records, summarize recorders and notify, used by
+// replay instead of checker loop
+func (n *node) Check() {
+	rs := n.recorders()
+	for _, r := range []recorder.Recorder{rs.transaction, rs.block, rs.confirmation, rs.command, rs.heartbeat} {
+		r.RemoveExpired()
+	}
+
+	checkTransaction(n, rs)
+	checkBlock(n, rs)
+	checkConfirmation(n, rs)
+	checkCommand(n, rs)
+	checkDisconnect(n)
+	checkHeartbeat(n, rs)
+}
+
+// Process - process broadcast received at specific time
+func (n *node) Process(data [][]byte, receivedAt time.Time) {
+	process(n, n.recorders(), data, receivedAt)
+}
+
+func (n *node) recorders() recorders {
+	return recorders{
+		heartbeat:    n.heartbeatRecorder,
+		transaction:  n.transactionRecorder,
+		block:        n.blockRecorder,
+		confirmation: n.confirmationRecorder,
+		command:      n.commandRecorder,
+		watchdog:     n.watchdog,
+	}
+}
+
 // Name - return node name
 func (n *node) Name() string {
 	return n.name
@@ -0,0 +1,291 @@
+package node
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/alert"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/bot"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/fault"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/recorder"
+)
+
+const (
+	queryTimeFormat = "2006-01-02 15:04 MST"
+
+	// prefixes of silence target, target without prefix is node name
+	tagPrefix       = "tag:"
+	conditionPrefix = "alert:"
+)
+
+// querier - answer bot commands from node status and alert manager
+type querier struct {
+	nodes []Node
+}
+
+// NewQuerier - querier of node states for bot
+func NewQuerier(nodes []Node) bot.Querier {
+	return &querier{nodes: nodes}
+}
+
+// Fleet - one line of each node, incidents of nodes of each chain as a
+// whole and active silences
+func (q *querier) Fleet() string {
+	incidents := alerts.Incidents()
+	silences := alerts.Silences()
+
+	lines := make([]string, 0, len(q.nodes)+len(silences)+1)
+	for _, n := range q.nodes {
+		lines = append(lines, overview(n, incidents, silences))
+	}
+
+	for _, chain := range q.chains() {
+		name := fleetOf(chain)
+		if conditions := conditionsOf(name, incidents); 0 < len(conditions) {
+			lines = append(lines, fmt.Sprintf("%s: incidents %s", name, strings.Join(conditions, ", ")))
+		}
+	}
+
+	for _, silence := range silences {
+		lines = append(lines, silenceLine(silence))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Node - overview, latest summaries and open incidents of node
+func (q *querier) Node(name string) (string, error) {
+	n := q.find(name)
+	if nil == n {
+		return "", fault.UnknownNode
+	}
+
+	incidents := alerts.Incidents()
+	silences := alerts.Silences()
+	lines := []string{overview(n, incidents, silences)}
+
+	s := snapshot(name)
+	for _, kind := range summaryKinds {
+		if summary, ok := s.summaries[kind]; ok {
+			lines = append(lines, fmt.Sprintf("%s: %s", kind, summary))
+		}
+	}
+
+	for _, i := range incidents {
+		if i.Node == name {
+			lines = append(lines, fmt.Sprintf(
+				"%s since %s: %s",
+				i.Condition,
+				i.OpenedAt.Format(queryTimeFormat),
+				i.Message,
+			))
+		}
+	}
+
+	for _, silence := range silences {
+		if selects(silence, name) {
+			lines = append(lines, silenceLine(silence))
+		}
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// Forks - open fork incidents
+func (q *querier) Forks() string {
+	lines := make([]string, 0)
+	for _, i := range alerts.Incidents() {
+		if forkCondition == i.Condition {
+			lines = append(lines, fmt.Sprintf("%s: %s", i.Node, i.Message))
+		}
+	}
+
+	if 0 == len(lines) {
+		return "no fork"
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Heights - polled and broadcast heights of nodes, and blocks behind best
+// height of same chain
+func (q *querier) Heights() string {
+	snapshots := make([]status, 0, len(q.nodes))
+	best := make(map[string]uint64)
+	for _, n := range q.nodes {
+		s := snapshot(n.Name())
+		snapshots = append(snapshots, s)
+		if s.height > best[s.chain] {
+			best[s.chain] = s.height
+		}
+	}
+
+	lines := make([]string, 0, len(q.nodes))
+	for i, n := range q.nodes {
+		s := snapshots[i]
+		if 0 == s.height {
+			lines = append(lines, fmt.Sprintf("%s: height unknown, broadcast %d", n.Name(), s.broadcastHeight))
+			continue
+		}
+
+		lines = append(lines, fmt.Sprintf(
+			"%s: height %d, broadcast %d, behind %d",
+			n.Name(),
+			s.height,
+			s.broadcastHeight,
+			behind(s.height, best[s.chain]),
+		))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Silence - suppress alerts of target for duration, target is node name,
+// tag:<tag> or alert:<condition>, silence is named by target
+func (q *querier) Silence(target string, d time.Duration) (string, error) {
+	now := time.Now()
+	silence := alert.Silence{
+		Name:  target,
+		Start: now,
+		End:   now.Add(d),
+	}
+
+	switch {
+	case strings.HasPrefix(target, tagPrefix) && tagPrefix != target:
+		silence.Tags = []string{strings.TrimPrefix(target, tagPrefix)}
+
+	case strings.HasPrefix(target, conditionPrefix) && conditionPrefix != target:
+		silence.Conditions = []string{strings.TrimPrefix(target, conditionPrefix)}
+
+	case nil == q.find(target):
+		return "", fault.UnknownNode
+
+	default:
+		silence.Nodes = []string{target}
+	}
+
+	alerts.Silence(silence)
+	return fmt.Sprintf("silence %s until %s", target, silence.End.Format(queryTimeFormat)), nil
+}
+
+// Unsilence - lift silence of name
+func (q *querier) Unsilence(name string) (string, error) {
+	if err := alerts.Unsilence(name); nil != err {
+		return "", err
+	}
+	return fmt.Sprintf("silence %s lifted", name), nil
+}
+
+// Acknowledge - stop repeating open incident of condition of node
+func (q *querier) Acknowledge(name string, condition string) (string, error) {
+	if err := alerts.Acknowledge(name, condition); nil != err {
+		return "", err
+	}
+	return fmt.Sprintf("incident %s of %s acknowledged", condition, name), nil
+}
+
+func (q *querier) find(name string) Node {
+	for _, n := range q.nodes {
+		if n.Name() == name {
+			return n
+		}
+	}
+	return nil
+}
+
+// chains - sorted chains of nodes
+func (q *querier) chains() []string {
+	seen := make(map[string]struct{})
+	chains := make([]string, 0)
+	for _, n := range q.nodes {
+		chain := chainOf(n.Name())
+		if _, ok := seen[chain]; ok {
+			continue
+		}
+		seen[chain] = struct{}{}
+		chains = append(chains, chain)
+	}
+	sort.Strings(chains)
+	return chains
+}
+
+// overview - liveness, mode, version, height, incidents and silence of node
+func overview(n Node, incidents []alert.Incident, silences []alert.Silence) string {
+	parts := []string{liveness(n.Name(), incidents)}
+
+	s := snapshot(n.Name())
+	switch {
+	case nil == s.info:
+		parts = append(parts, "mode unknown")
+	case s.info.Normal:
+		parts = append(parts, "normal", fmt.Sprintf("version %s", s.info.Version))
+	default:
+		parts = append(parts, "not normal", fmt.Sprintf("version %s", s.info.Version))
+	}
+
+	if 0 < s.height {
+		parts = append(parts, fmt.Sprintf("height %d", s.height))
+	}
+
+	if conditions := conditionsOf(n.Name(), incidents); 0 < len(conditions) {
+		parts = append(parts, fmt.Sprintf("incidents %s", strings.Join(conditions, ", ")))
+	}
+
+	// silence of some conditions only is listed in status of node
+	for _, silence := range silences {
+		if 0 == len(silence.Conditions) && selects(silence, n.Name()) {
+			parts = append(parts, fmt.Sprintf("silenced until %s", silence.End.Format(queryTimeFormat)))
+			break
+		}
+	}
+
+	return fmt.Sprintf("%s: %s", n.Name(), strings.Join(parts, ", "))
+}
+
+// liveness - silent while watchdog incident is open, otherwise age of last
+// broadcast received
+func liveness(name string, incidents []alert.Incident) string {
+	for _, i := range incidents {
+		if i.Node == name && nodeSilentCondition == i.Condition {
+			return "silent"
+		}
+	}
+
+	received := lastReceivedOf(name)
+	if received.IsZero() {
+		return "no broadcast"
+	}
+	return fmt.Sprintf("last broadcast %s ago", currentTime().Sub(received).Truncate(time.Second))
+}
+
+// selects - silence selects node by name or tags, any condition
+func selects(silence alert.Silence, name string) bool {
+	silence.Conditions = nil
+	return silence.Matches(name, tagsOf(name), "")
+}
+
+func silenceLine(silence alert.Silence) string {
+	return fmt.Sprintf("silence %s until %s", silence, silence.End.Format(queryTimeFormat))
+}
+
+func conditionsOf(name string, incidents []alert.Incident) []string {
+	conditions := make([]string, 0)
+	for _, i := range incidents {
+		if i.Node == name {
+			conditions = append(conditions, i.Condition)
+		}
+	}
+	return conditions
+}
+
+// snapshot - copy of node status
+func snapshot(name string) status {
+	statusMutex.Lock()
+	defer statusMutex.Unlock()
+
+	s := *statusOf(name)
+	summaries := make(map[string]recorder.SummaryOutput)
+	for kind, summary := range s.summaries {
+		summaries[kind] = summary
+	}
+	s.summaries = summaries
+	return s
+}
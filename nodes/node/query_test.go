@@ -0,0 +1,136 @@
+package node
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/alert"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/configuration"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/fault"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/recorder"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupQuerier() *querier {
+	initialiseStatus()
+	alerts = alert.NewManager(nil, nil, time.Hour, time.Second)
+
+	return NewQuerier([]Node{
+		&node{name: "a"},
+		&node{name: "b"},
+		&node{name: "c"},
+	}).(*querier)
+}
+
+func TestQuerierFleet(t *testing.T) {
+	q := setupQuerier()
+
+	statusMutex.Lock()
+	statusOf("a").info = testInfo("1.0", true)
+	statusOf("b").info = testInfo("1.1", false)
+	statusMutex.Unlock()
+	updateHeight("a", "testing", 100)
+	received("a", time.Now().Add(-90*time.Second))
+
+	fire("b", notNormalCondition, "not in normal mode")
+	fire("c", nodeSilentCondition, "no heartbeat")
+	fireOnChain(fleetOf("testing"), "testing", versionDriftCondition, "version drift")
+
+	expected := "a: last broadcast 1m30s ago, normal, version 1.0, height 100\n" +
+		"b: no broadcast, not normal, version 1.1, incidents not-normal\n" +
+		"c: silent, mode unknown, incidents node-silent\n" +
+		"fleet-testing: incidents version-drift"
+	assert.Equal(t, expected, q.Fleet(), "wrong fleet")
+}
+
+func TestQuerierNode(t *testing.T) {
+	q := setupQuerier()
+
+	keepSummary("a", blockSummary, &recorder.BlocksSummary{})
+	fire("a", lagCondition, "5 blocks behind")
+
+	_, err := q.Node("d")
+	assert.Equal(t, fault.UnknownNode, err, "wrong error")
+
+	actual, err := q.Node("a")
+	assert.Nil(t, err, "wrong error")
+	assert.Contains(t, actual, "a: no broadcast, mode unknown, incidents lag", "wrong overview")
+	assert.Contains(t, actual, "block: ", "wrong summary")
+	assert.Contains(t, actual, ": 5 blocks behind", "wrong incident")
+}
+
+func TestQuerierForks(t *testing.T) {
+	q := setupQuerier()
+	assert.Equal(t, "no fork", q.Forks(), "wrong no fork")
+
+	fire(forkKey("b", "a"), forkCondition, "split at block 10")
+	assert.Equal(t, "a-b: split at block 10", q.Forks(), "wrong forks")
+}
+
+func TestQuerierHeights(t *testing.T) {
+	q := setupQuerier()
+
+	updateHeight("a", "testing", 100)
+	updateHeight("b", "testing", 95)
+	updateBroadcastHeight("b", "testing", 96)
+
+	expected := "a: height 100, broadcast 0, behind 0\n" +
+		"b: height 95, broadcast 96, behind 5\n" +
+		"c: height unknown, broadcast 0"
+	assert.Equal(t, expected, q.Heights(), "wrong heights")
+}
+
+func TestQuerierSilence(t *testing.T) {
+	q := setupQuerier()
+
+	_, err := q.Silence("d", time.Hour)
+	assert.Equal(t, fault.UnknownNode, err, "wrong error")
+
+	_, err = q.Silence("a", time.Hour)
+	assert.Nil(t, err, "wrong error")
+	assert.Contains(t, q.Fleet(), "a: no broadcast, mode unknown, silenced until", "wrong silence")
+}
+
+func TestQuerierSilenceOfTagAndCondition(t *testing.T) {
+	q := setupQuerier()
+	registerNode(configuration.NodeConfig{Name: "a", Chain: "bitmark", Tags: []string{"mainnet"}})
+
+	_, err := q.Silence("tag:mainnet", time.Hour)
+	assert.Nil(t, err, "wrong error")
+	_, err = q.Silence("alert:blocks", time.Hour)
+	assert.Nil(t, err, "wrong error")
+
+	fleet := q.Fleet()
+	assert.Contains(t, fleet, "a: no broadcast, mode unknown, silenced until", "wrong silence of tag")
+	assert.Contains(t, fleet, "silence alert:blocks of alerts blocks until", "wrong silence of condition")
+	assert.Contains(t, fleet, "silence tag:mainnet of tags mainnet until", "wrong silence of tag")
+
+	actual, _ := q.Node("b")
+	assert.NotContains(t, actual, "tag:mainnet", "wrong silence of other tag")
+	assert.Contains(t, actual, "alert:blocks", "wrong silence of condition")
+}
+
+func TestQuerierUnsilence(t *testing.T) {
+	q := setupQuerier()
+
+	_, err := q.Unsilence("a")
+	assert.Equal(t, fault.UnknownSilence, err, "wrong error")
+
+	_, _ = q.Silence("a", time.Hour)
+	_, err = q.Unsilence("a")
+	assert.Nil(t, err, "wrong error")
+	assert.NotContains(t, q.Fleet(), "silence", "wrong silence not lifted")
+}
+
+func TestQuerierAcknowledge(t *testing.T) {
+	q := setupQuerier()
+
+	_, err := q.Acknowledge("a", lagCondition)
+	assert.Equal(t, fault.UnknownIncident, err, "wrong error")
+
+	fire("a", lagCondition, "5 blocks behind")
+	actual, err := q.Acknowledge("a", lagCondition)
+	assert.Nil(t, err, "wrong error")
+	assert.Equal(t, "incident lag of a acknowledged", actual, "wrong reply")
+	assert.True(t, alerts.Incidents()[0].Acknowledged(), "wrong incident not acknowledged")
+}
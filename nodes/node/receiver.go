@@ -13,9 +13,7 @@ import (
 	"github.com/bitmark-inc/bitmarkd/merkle"
 	"github.com/bitmark-inc/bitmarkd/transactionrecord"
 	"github.com/bitmark-inc/logger"
-	"github.com/jamieabc/bitmarkd-broadcast-monitor/clock"
-	"github.com/jamieabc/bitmarkd-broadcast-monitor/network"
-	zmq "github.com/pebbe/zmq4"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/capture"
 )
 
 const (
@@ -26,27 +24,25 @@ const (
 	heartbeatCmdStr           = "heart"
 	pollerTimeoutSecond       = 30 * time.Second
 	transactionTimeoutSecond  = 120 * time.Second
-	eventChannelSize          = 100
+	frameChannelSize          = 1000
 	reconnectDelayMillisecond = 20 * time.Millisecond
 	keyLength                 = 10
 )
 
 func receiverLoop(args []interface{}) {
-	if 3 != len(args) {
+	if 2 != len(args) {
 		fmt.Println("receiverLoop wrong argument length")
 		return
 	}
 	n := args[0].(Node)
 	rs := args[1].(recorders)
-	id := args[2].(int)
 	log := n.Log()
-	timer := clock.NewClock()
 
-	task.Go(rs.transaction.PeriodicRemove, timer, ctx.Done())
-	task.Go(rs.block.PeriodicRemove, timer, ctx.Done())
-	task.Go(receiverRoutine, n, rs, id)
+	done := make(chan struct{})
+	task.Go(receiverRoutine, n, rs, done)
 
-	<-ctx.Done()
+	// socket is closed after poller stops using it
+	<-done
 
 	if err := n.Close(); nil != err {
 		log.Errorf("close connection with error: %s", err)
@@ -63,41 +59,31 @@ func receiverRoutine(args []interface{}) {
 	}
 	n := args[0].(Node)
 	rs := args[1].(recorders)
-	id := args[2].(int)
-	eventChan := make(chan zmq.Polled, eventChannelSize)
+	done := args[2].(chan struct{})
+	frameChan := make(chan [][]byte, frameChannelSize)
 	log := n.Log()
 	transactionTimer := time.NewTimer(transactionTimeoutSecond)
 
-	poller, err := initialisePoller(n, id, eventChan)
-	if nil != err {
-		log.Errorf("initialise poller with error: %s", err)
-		return
-	}
-
-	task.Go(poller.Start, pollerTimeoutSecond, ctx.Done())
+	poller.Add(n.BroadcastReceiver(), frameChan)
 
 	for {
-		log.Debug("waiting events...")
+		log.Debug("waiting frames...")
 		select {
-		case polled := <-eventChan:
-			data, err := polled.Socket.RecvMessageBytes(-1)
-			if nil != err {
-				log.Errorf("receive message with error: %s", err)
-				//error might comes from reopen socket, will behave normal after some retries
-				continue
-			}
-			process(n, rs, data)
+		case data := <-frameChan:
+			now := time.Now()
+			captureFrame(n, data, now)
+			process(n, rs, data, now)
 			resetTimer(transactionTimer, log)
 
 		case <-ctx.Done():
 			log.Infof("terminate receiver loop")
-			close(eventChan)
+			poller.Remove(n.BroadcastReceiver())
+			close(done)
 			return
 
 		case <-transactionTimer.C:
 			log.Warn("transaction timeout exceed, reset timer")
-			//reconnect(poller, n, transactionTimer)
-			resetTimer(transactionTimer, log)
+			reconnect(n, frameChan, transactionTimer)
 		}
 	}
 }
@@ -114,43 +100,56 @@ func resetTimer(t *time.Timer, log *logger.L) {
 }
 
 //sometimes not receiving transaction for some time, then need to close the socket and open a new one
-func reconnect(poller network.Poller, n Node, transactionTimer *time.Timer) {
+func reconnect(n Node, frameChan chan<- [][]byte, transactionTimer *time.Timer) {
 	log := n.Log()
 
 	log.Info("closing broadcast receiver connection")
+
+	// poller stops reading old socket before it is closed
 	poller.Remove(n.BroadcastReceiver())
 	err := n.BroadcastReceiver().Reconnect()
 	if nil != err {
-		n.Log().Errorf("reconnect with error: %s, abort", err)
-		return
+		log.Errorf("reconnect with error: %s, retry after timeout", err)
+	} else {
+		time.Sleep(reconnectDelayMillisecond)
+		log.Infof("adding socket %s to poller", n.BroadcastReceiver().String())
+		poller.Add(n.BroadcastReceiver(), frameChan)
 	}
-	time.Sleep(reconnectDelayMillisecond)
-	log.Infof("adding socket %s to poller", n.BroadcastReceiver().String())
-	poller.Add(n.BroadcastReceiver(), zmq.POLLIN)
 	log.Debug("reset transaction timer")
 	transactionTimer.Reset(transactionTimeoutSecond)
 }
 
-func initialisePoller(n Node, id int, eventChan chan zmq.Polled) (network.Poller, error) {
-	pollerShutdownChan := make(chan struct{}, 1)
-	poller, err := network.NewPoller(eventChan, pollerShutdownChan, id)
+func captureFrame(n Node, data [][]byte, receivedAt time.Time) {
+	if nil == capturer {
+		return
+	}
+
+	err := capturer.Write(capture.Frame{
+		Name:       n.Name(),
+		ReceivedAt: receivedAt,
+		Data:       data,
+	})
 	if nil != err {
-		return nil, err
+		n.Log().Errorf("capture frame with error: %s", err)
 	}
-	poller.Add(n.BroadcastReceiver(), zmq.POLLIN)
-	return poller, nil
 }
 
-func process(n Node, rs recorders, data [][]byte) {
+func process(n Node, rs recorders, data [][]byte, now time.Time) {
 	log := n.Log()
 	blockchain := string(data[0])
 	if !chain.Valid(blockchain) {
 		log.Errorf("invalid chain: %s", blockchain)
 		return
 	}
-	now := time.Now()
+	received(n.Name(), now)
+
+	category := string(data[1])
+	if !n.Expect(category) {
+		log.Debugf("drop %s, not subscribed", category)
+		return
+	}
 
-	switch category := string(data[1]); category {
+	switch category {
 	case blockCmdStr:
 		key := string(data[2][0:keyLength])
 
@@ -169,6 +168,7 @@ func process(n Node, rs recorders, data [][]byte) {
 		}
 
 		log.Infof("receive block %d", header.Number)
+		updateBroadcastHeight(n.Name(), blockchain, header.Number)
 		rs.block.Add(now, recorder.BlockData{
 			Number:       header.Number,
 			GenerateTime: time.Unix(int64(header.Timestamp), 0),
@@ -191,9 +191,12 @@ func process(n Node, rs recorders, data [][]byte) {
 		}
 
 		log.Infof("receive %s broadcast, ID %s", category, []byte(fmt.Sprintf("%v", id)))
+		rs.confirmation.Add(now, recorder.TransactionBroadcast{ID: id.String()})
 
 	case heartbeatCmdStr:
 		log.Infof("receive heartbeat")
+		rs.heartbeat.Add(now)
+		rs.watchdog.beat(now)
 
 	default:
 		log.Debugf("receive %s", category)
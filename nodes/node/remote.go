@@ -1,36 +1,58 @@
 package node
 
 import (
+	"context"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/jamieabc/bitmarkd-broadcast-monitor/communication"
 	"github.com/jamieabc/bitmarkd-broadcast-monitor/configuration"
 	"github.com/jamieabc/bitmarkd-broadcast-monitor/network"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/recorder"
 	zmq "github.com/pebbe/zmq4"
 )
 
 // Remote - remote interface
 type Remote interface {
-	BlockHeader(uint64) (*communication.BlockHeaderResponse, error)
+	Block(context.Context, uint64) (*communication.BlockResponse, error)
+	BlockDigest(context.Context, uint64) (*communication.BlockDigestResponse, error)
+	BlockHeader(context.Context, uint64) (*communication.BlockHeaderResponse, error)
 	BroadcastReceiver() network.Client
 	Close() error
 	CommandSender() network.Client
-	Info() (*communication.InfoResponse, error)
-	Height() (*communication.HeightResponse, error)
+	Info(context.Context) (*communication.InfoResponse, error)
+	Height(context.Context) (*communication.HeightResponse, error)
 }
 
+// command socket is REQ, every request and reply pair is serialised since
+// other nodes' fork locator also sends commands
 type remote struct {
+	sync.Mutex
 	broadcastReceiver network.Client
+	comm              communication.Communication
+	commandRecorder   recorder.Recorder
 	commandSender     network.Client
 }
 
+const (
+	// longest wait of a command reply, a block is at most few MB
+	commandTimeout = 30 * time.Second
+
+	infoCommand        = "info"
+	heightCommand      = "height"
+	blockHeaderCommand = "block-header"
+	blockDigestCommand = "block-digest"
+	blockCommand       = "block"
+)
+
 type connectionInfo struct {
 	addressAndPort string
 	chain          string
 	zmqType        zmq.Type
 }
 
-func newClient(config configuration.NodeConfig, nodeKey *nodeKeys) (Remote, error) {
+func newClient(config configuration.NodeConfig, nodeKey *nodeKeys, commandRecorder recorder.Recorder) (Remote, error) {
 	broadcastReceiver, err := newZmqClient(nodeKey, connectionInfo{
 		addressAndPort: broadcastAddressAndPort(config),
 		chain:          config.Chain,
@@ -53,10 +75,16 @@ func newClient(config configuration.NodeConfig, nodeKey *nodeKeys) (Remote, erro
 		}
 	}
 
-	return &remote{
+	r := &remote{
 		broadcastReceiver: broadcastReceiver,
+		commandRecorder:   commandRecorder,
 		commandSender:     commandSenderAndReceiver,
-	}, nil
+	}
+	if nil != commandSenderAndReceiver {
+		r.comm = communication.New(commandSenderAndReceiver, config.Chain)
+	}
+
+	return r, nil
 }
 
 func newZmqClient(nodeKey *nodeKeys, info connectionInfo) (client network.Client, err error) {
@@ -135,32 +163,91 @@ func (r *remote) CommandSender() network.Client {
 }
 
 // Info - remote info
-func (r *remote) Info() (*communication.InfoResponse, error) {
-	comm := communication.New(communication.ComInfo, r.commandSender)
-	reply, err := comm.Get()
-	if nil != err {
-		return nil, err
-	}
+func (r *remote) Info(ctx context.Context) (*communication.InfoResponse, error) {
+	r.Lock()
+	defer r.Unlock()
 
-	return reply.(*communication.InfoResponse), nil
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+
+	start := time.Now()
+	reply, err := r.comm.Info(ctx)
+	r.record(infoCommand, start, err)
+
+	return reply, err
 }
 
 // Height - remote height
-func (r *remote) Height() (*communication.HeightResponse, error) {
-	comm := communication.New(communication.ComHeight, r.commandSender)
-	reply, err := comm.Get()
-	if nil != err {
-		return nil, err
-	}
-	return reply.(*communication.HeightResponse), nil
+func (r *remote) Height(ctx context.Context) (*communication.HeightResponse, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+
+	start := time.Now()
+	reply, err := r.comm.Height(ctx)
+	r.record(heightCommand, start, err)
+
+	return reply, err
 }
 
 // BlockHeader - block header
-func (r *remote) BlockHeader(height uint64) (*communication.BlockHeaderResponse, error) {
-	comm := communication.New(communication.ComBlockHeader, r.commandSender)
-	reply, err := comm.Get(height)
-	if nil != err {
-		return nil, err
+func (r *remote) BlockHeader(ctx context.Context, height uint64) (*communication.BlockHeaderResponse, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+
+	start := time.Now()
+	reply, err := r.comm.BlockHeader(ctx, height)
+	r.record(blockHeaderCommand, start, err)
+
+	return reply, err
+}
+
+// BlockDigest - block digest
+func (r *remote) BlockDigest(ctx context.Context, height uint64) (*communication.BlockDigestResponse, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+
+	start := time.Now()
+	reply, err := r.comm.BlockDigest(ctx, height)
+	r.record(blockDigestCommand, start, err)
+
+	return reply, err
+}
+
+// Block - block with transaction IDs
+func (r *remote) Block(ctx context.Context, height uint64) (*communication.BlockResponse, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+
+	start := time.Now()
+	reply, err := r.comm.Block(ctx, height)
+	r.record(blockCommand, start, err)
+
+	return reply, err
+}
+
+// record - round trip latency and result of command, recorder tells not
+// found reply, timeout and cancellation of monitor apart from failure
+func (r *remote) record(command string, start time.Time, err error) {
+	if nil == r.commandRecorder {
+		return
 	}
-	return reply.(*communication.BlockHeaderResponse), nil
+
+	now := time.Now()
+	r.commandRecorder.Add(now, recorder.CommandResult{
+		Command: command,
+		Latency: now.Sub(start),
+		Err:     err,
+	})
 }
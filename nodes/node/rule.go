@@ -0,0 +1,82 @@
+package node
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/alert"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/configuration"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/fault"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/messengers"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/rule"
+)
+
+const (
+	disconnectCheckMinute = 1 * time.Minute
+)
+
+// newRules - rules of config, name of rule is condition of alert so it must
+// not be built in condition, messengers targeted by rule must exist
+func newRules(
+	configs []configuration.RuleConfig,
+	senders map[string]messengers.Messenger,
+	paging map[string]messengers.IncidentMessenger,
+) (rule.Rules, error) {
+	for _, c := range configs {
+		if _, ok := conditions[c.Name]; ok {
+			fmt.Printf("rule %q takes name of built in condition\n", c.Name)
+			return nil, fault.InvalidRuleName
+		}
+
+		for _, name := range c.Messengers {
+			if !exist(name, senders, paging) {
+				fmt.Printf("rule %q targets unknown messenger %q\n", c.Name, name)
+				return nil, fault.InvalidRuleMessenger
+			}
+		}
+	}
+	return rule.New(configs)
+}
+
+// ruled - metric of node is evaluated by configured rule, built in
+// condition of same metric is skipped so one event opens one incident
+func ruled(name string, metric string) bool {
+	return nil != rules && rules.Covers(name, metric)
+}
+
+// evaluate - fire or resolve alert of each rule on metric of node, condition
+// of alert is rule name
+func evaluate(name string, metric string, value float64) {
+	if nil == rules {
+		return
+	}
+
+	for _, r := range rules.Evaluate(name, metric, value, currentTime()) {
+		if !r.Firing {
+			resolve(name, r.Name)
+			continue
+		}
+
+		alerts.Fire(alert.Alert{
+			Node:       name,
+			Condition:  r.Name,
+			Message:    render(name, r.Name, r),
+			Severity:   r.Severity,
+			Messengers: r.Messengers,
+			Chain:      chainOf(name),
+			Tags:       tagsOf(name),
+		})
+	}
+}
+
+// reset - resolve rules of metric on node, metric not meaningful for now
+// must not keep duration of earlier comparison
+func reset(name string, metric string) {
+	if nil == rules {
+		return
+	}
+
+	for _, r := range rules.Reset(name, metric) {
+		resolve(name, r.Name)
+	}
+}
@@ -0,0 +1,35 @@
+package node
+
+import (
+	"testing"
+
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/configuration"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/fault"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/messengers"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/rule"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRules(t *testing.T) {
+	senders := map[string]messengers.Messenger{
+		"mainnet": messengers.NewSlack(configuration.SlackConfig{}),
+	}
+
+	_, err := newRules([]configuration.RuleConfig{
+		{Name: "missing", Metric: rule.MissingBlocks, Comparison: ">", Messengers: []string{"mainnet"}},
+		{Name: "heartbeat", Metric: rule.HeartbeatDropRate, Comparison: ">", Threshold: 0.5},
+	}, senders, nil)
+	assert.Nil(t, err, "wrong error")
+
+	_, err = newRules([]configuration.RuleConfig{
+		{Name: "missing", Metric: rule.MissingBlocks, Comparison: ">", Messengers: []string{"testnet"}},
+	}, senders, nil)
+	assert.Equal(t, fault.InvalidRuleMessenger, err, "wrong error of unknown messenger")
+
+	for _, name := range []string{lagCondition, forkCondition, nodeSilentCondition} {
+		_, err = newRules([]configuration.RuleConfig{
+			{Name: name, Metric: rule.Lag, Comparison: ">"},
+		}, senders, nil)
+		assert.Equal(t, fault.InvalidRuleName, err, "wrong error of built in condition %s", name)
+	}
+}
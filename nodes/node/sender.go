@@ -8,20 +8,24 @@ import (
 
 	"github.com/bitmark-inc/bitmarkd/blockdigest"
 	"github.com/jamieabc/bitmarkd-broadcast-monitor/communication"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/recorder"
 )
 
 const (
 	checkIntervalSecond = 5 * time.Minute
+	maxFetchBlocks      = 10
 )
 
 func senderLoop(args []interface{}) {
-	if 1 != len(args) {
+	if 2 != len(args) {
 		fmt.Println("senderLoop wrong argument length")
 		return
 	}
 	n := args[0].(Node)
+	rs := args[1].(recorders)
 	log := n.Log()
 	timer := time.NewTimer(checkIntervalSecond)
+	var lastBlock uint64
 
 	for {
 		select {
@@ -30,26 +34,79 @@ func senderLoop(args []interface{}) {
 			return
 
 		case <-timer.C:
+			timer.Reset(checkIntervalSecond)
+
 			info, err := remoteInfo(n)
 			if nil != err {
 				log.Errorf("get remote info error: %s", err)
 				continue
 			}
 			log.Infof("remote info: %s", info)
+			checkInfo(n, info)
+
 			header, digest, err := remoteBlockHeader(n, info.Height)
+			if nil != err {
+				log.Errorf("get remote block header error: %s", err)
+				continue
+			}
 			log.Infof(
 				"remote height %d with digest %s, generated at %s",
 				info.Height,
 				digest,
 				time.Unix(int64(header.Timestamp), 0),
 			)
-			timer.Reset(checkIntervalSecond)
+
+			checkForks(n, tip{
+				height: info.Height,
+				digest: digest,
+			})
+
+			lastBlock = fetchBlocks(n, rs, lastBlock, info.Height)
 		}
 	}
 }
 
+// fetchBlocks - fetch blocks after last fetched one up to height for matching
+// broadcast transactions, at most maxFetchBlocks each time, returns last
+// fetched block
+func fetchBlocks(n Node, rs recorders, last uint64, height uint64) uint64 {
+	if height == last {
+		return last
+	}
+
+	from := last + 1
+	if height < last {
+		from = height
+	}
+	if height-from >= maxFetchBlocks {
+		n.Log().Warnf("skip blocks %d to %d", from, height-maxFetchBlocks)
+		from = height - maxFetchBlocks + 1
+	}
+
+	for h := from; h <= height; h++ {
+		block, err := n.Remote().Block(ctx, h)
+		if nil != err {
+			n.Log().Errorf("get remote block %d with error: %s", h, err)
+			return h - 1
+		}
+
+		ids := make([]string, 0, len(block.Transactions))
+		for _, id := range block.Transactions {
+			ids = append(ids, id.String())
+		}
+
+		n.Log().Debugf("block %d has %d transactions", h, len(ids))
+		rs.confirmation.Add(time.Now(), recorder.BlockTransactions{
+			Number:       block.Header.Number,
+			GenerateTime: time.Unix(int64(block.Header.Timestamp), 0),
+			IDs:          ids,
+		})
+	}
+	return height
+}
+
 func remoteInfo(n Node) (*communication.InfoResponse, error) {
-	info, err := n.Remote().Info()
+	info, err := n.Remote().Info(ctx)
 	if nil != err {
 		return nil, err
 	}
@@ -57,7 +114,7 @@ func remoteInfo(n Node) (*communication.InfoResponse, error) {
 }
 
 func remoteHeight(n Node) (uint64, error) {
-	height, err := n.Remote().Height()
+	height, err := n.Remote().Height(ctx)
 	if nil != err {
 		return uint64(0), err
 	}
@@ -65,7 +122,7 @@ func remoteHeight(n Node) (uint64, error) {
 }
 
 func remoteBlockHeader(n Node, height uint64) (*blockrecord.Header, blockdigest.Digest, error) {
-	resp, err := n.Remote().BlockHeader(height)
+	resp, err := n.Remote().BlockHeader(ctx, height)
 	if nil != err {
 		return &blockrecord.Header{}, blockdigest.Digest{}, err
 	}
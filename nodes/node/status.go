@@ -0,0 +1,274 @@
+package node
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/communication"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/configuration"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/db"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/recorder"
+)
+
+const (
+	infoMeasurement = "node-info"
+	fleetName       = "fleet"
+)
+
+// kinds of recorder summary kept in status
+const (
+	transactionSummary  = "transaction"
+	blockSummary        = "block"
+	confirmationSummary = "confirmation"
+	commandSummary      = "command"
+	heartbeatSummary    = "heartbeat"
+)
+
+var summaryKinds = []string{
+	transactionSummary,
+	blockSummary,
+	confirmationSummary,
+	commandSummary,
+	heartbeatSummary,
+}
+
+// status - latest state reported by node command port
+type status struct {
+	chain           string
+	config          configuration.NodeConfig
+	info            *communication.InfoResponse
+	height          uint64
+	broadcastHeight uint64
+	lag             lagState
+	lastReceived    time.Time
+	summaries       map[string]recorder.SummaryOutput
+}
+
+var (
+	statusMutex sync.Mutex
+	statuses    map[string]*status
+)
+
+func initialiseStatus() {
+	statusMutex.Lock()
+	defer statusMutex.Unlock()
+
+	statuses = make(map[string]*status)
+}
+
+// statusOf - caller holds statusMutex
+func statusOf(name string) *status {
+	s, ok := statuses[name]
+	if !ok {
+		s = &status{summaries: make(map[string]recorder.SummaryOutput)}
+		statuses[name] = s
+	}
+	return s
+}
+
+// keepSummary - latest summary of recorder, summary of recorder can only be
+// taken once by checker
+func keepSummary(name string, kind string, summary recorder.SummaryOutput) {
+	statusMutex.Lock()
+	defer statusMutex.Unlock()
+
+	statusOf(name).summaries[kind] = summary
+}
+
+// registerNode - config of node, routes and silences match alerts by chain
+// and tags, message templates render config
+func registerNode(config configuration.NodeConfig) {
+	statusMutex.Lock()
+	defer statusMutex.Unlock()
+
+	s := statusOf(config.Name)
+	s.chain = config.Chain
+	s.config = config
+}
+
+// configOf - config of node, empty for fleet or unknown node
+func configOf(name string) configuration.NodeConfig {
+	statusMutex.Lock()
+	defer statusMutex.Unlock()
+
+	s, ok := statuses[name]
+	if !ok {
+		return configuration.NodeConfig{}
+	}
+	return s.config
+}
+
+// tagsOf - tags of node, empty for fleet or unknown node
+func tagsOf(name string) []string {
+	return configOf(name).Tags
+}
+
+// chainOf - chain of node, empty for fleet or unknown node
+func chainOf(name string) string {
+	statusMutex.Lock()
+	defer statusMutex.Unlock()
+
+	s, ok := statuses[name]
+	if !ok {
+		return ""
+	}
+	return s.chain
+}
+
+// received - broadcast received by node
+func received(name string, at time.Time) {
+	statusMutex.Lock()
+	defer statusMutex.Unlock()
+
+	s := statusOf(name)
+	if at.After(s.lastReceived) {
+		s.lastReceived = at
+	}
+}
+
+// lastReceivedOf - time of last broadcast received by node, zero if not
+// monitored yet
+func lastReceivedOf(name string) time.Time {
+	statusMutex.Lock()
+	defer statusMutex.Unlock()
+
+	return statusOf(name).lastReceived
+}
+
+// checkInfo - keep info of node, report changes of node and version drift
+// of nodes on same chain
+func checkInfo(n Node, info *communication.InfoResponse) {
+	log := n.Log()
+	writeInfoToInfluxDB(info, n.Name())
+
+	statusMutex.Lock()
+	s := statusOf(n.Name())
+	previous := s.info
+	s.info = info
+	chain := s.chain
+	versions := chainVersions(chain)
+	statusMutex.Unlock()
+
+	changes := infoChanges(n.Name(), previous, info)
+	drift := versionDrift(chain, versions)
+
+	if info.Normal {
+		resolve(n.Name(), notNormalCondition)
+	} else {
+		fire(n.Name(), notNormalCondition, render(n.Name(), notNormalCondition, info))
+	}
+
+	for _, msg := range changes {
+		log.Warn(msg)
+		notify(n.Name(), versionCondition, msg)
+	}
+
+	if "" == drift {
+		resolve(fleetOf(chain), versionDriftCondition)
+	} else {
+		log.Warn(drift)
+		fireOnChain(fleetOf(chain), chain, versionDriftCondition, drift)
+	}
+}
+
+// infoChanges - messages of version or chain changes between previous and
+// current info, changed version means node restarted
+func infoChanges(name string, previous *communication.InfoResponse, current *communication.InfoResponse) []string {
+	msgs := make([]string, 0)
+	if nil == previous {
+		return msgs
+	}
+
+	if previous.Version != current.Version {
+		msgs = append(msgs, render(name, versionCondition, infoChange{
+			Field: "version",
+			From:  previous.Version,
+			To:    current.Version,
+		}))
+	}
+
+	if previous.Chain != current.Chain {
+		msgs = append(msgs, render(name, versionCondition, infoChange{
+			Field: "chain",
+			From:  previous.Chain,
+			To:    current.Chain,
+		}))
+	}
+
+	return msgs
+}
+
+// chainVersions - version of nodes on chain with known info, caller holds
+// statusMutex
+func chainVersions(chain string) map[string]string {
+	versions := make(map[string]string)
+	for name, s := range statuses {
+		if s.chain == chain && nil != s.info {
+			versions[name] = s.info.Version
+		}
+	}
+	return versions
+}
+
+// fleetOf - name of nodes on chain as a whole, version drift of chain is
+// alerted on it
+func fleetOf(chain string) string {
+	return fmt.Sprintf("%s-%s", fleetName, chain)
+}
+
+// versionDrift - empty when all nodes of chain run same version, otherwise
+// nodes grouped by version
+func versionDrift(chain string, versions map[string]string) string {
+	groups := make(map[string][]string)
+	for name, version := range versions {
+		groups[version] = append(groups[version], name)
+	}
+
+	if 1 >= len(groups) {
+		return ""
+	}
+
+	keys := make([]string, 0, len(groups))
+	for version := range groups {
+		keys = append(keys, version)
+	}
+	sort.Strings(keys)
+
+	drift := make([]versionGroup, 0, len(keys))
+	for _, version := range keys {
+		names := groups[version]
+		sort.Strings(names)
+		drift = append(drift, versionGroup{Version: version, Nodes: names})
+	}
+	return render(fleetOf(chain), versionDriftCondition, drift)
+}
+
+// isNormal - node in normal mode, true when not yet known
+func isNormal(name string) bool {
+	statusMutex.Lock()
+	defer statusMutex.Unlock()
+
+	s, ok := statuses[name]
+	if !ok || nil == s.info {
+		return true
+	}
+	return s.info.Normal
+}
+
+func writeInfoToInfluxDB(info *communication.InfoResponse, name string) {
+	db.Add(db.InfluxData{
+		Fields: map[string]interface{}{
+			"version": info.Version,
+			"normal":  info.Normal,
+			"height":  int64(info.Height),
+		},
+		Measurement: infoMeasurement,
+		Tags: map[string]string{
+			"name":  name,
+			"chain": info.Chain,
+		},
+		Timing: time.Now(),
+	})
+}
@@ -0,0 +1,109 @@
+package node
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/bitmark-inc/logger"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/alert"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/communication"
+	"github.com/stretchr/testify/assert"
+)
+
+func testInfo(version string, normal bool) *communication.InfoResponse {
+	return &communication.InfoResponse{
+		Version: version,
+		Chain:   "testing",
+		Normal:  normal,
+		Height:  100,
+	}
+}
+
+func TestInfoChangesWhenFirstInfo(t *testing.T) {
+	assert.Equal(t, 0, len(infoChanges("a", nil, testInfo("1.0", false))), "wrong first info changes")
+}
+
+func TestInfoChangesWhenNormalChanged(t *testing.T) {
+	assert.Equal(t, 0, len(infoChanges("a", testInfo("1.0", true), testInfo("1.0", false))), "wrong mode changes")
+}
+
+func TestInfoChangesWhenVersionChanged(t *testing.T) {
+	msgs := infoChanges("a", testInfo("1.0", true), testInfo("1.1", true))
+	assert.Equal(t, 1, len(msgs), "wrong changes count")
+	assert.Equal(t, "version changed from 1.0 to 1.1", msgs[0], "wrong message")
+}
+
+func TestVersionDrift(t *testing.T) {
+	assert.Equal(t, "", versionDrift("testing", map[string]string{"a": "1.0", "b": "1.0"}), "wrong same version")
+	assert.Equal(
+		t,
+		"version drift: 1.0 (a, c), 1.1 (b)",
+		versionDrift("testing", map[string]string{"a": "1.0", "b": "1.1", "c": "1.0"}),
+		"wrong drift",
+	)
+}
+
+func TestChainVersions(t *testing.T) {
+	initialiseStatus()
+
+	statusMutex.Lock()
+	defer statusMutex.Unlock()
+
+	statusOf("a").chain = "testing"
+	statusOf("a").info = testInfo("1.0", true)
+	statusOf("b").chain = "testing"
+	assert.Equal(t, map[string]string{"a": "1.0"}, chainVersions("testing"), "wrong versions of unknown node")
+
+	statusOf("b").info = testInfo("1.1", true)
+	statusOf("c").chain = "bitmark"
+	statusOf("c").info = testInfo("2.0", true)
+	assert.Equal(t, map[string]string{"a": "1.0", "b": "1.1"}, chainVersions("testing"), "wrong versions")
+	assert.Equal(t, map[string]string{"c": "2.0"}, chainVersions("bitmark"), "wrong versions of other chain")
+}
+
+func TestCheckInfoVersionDriftOfChain(t *testing.T) {
+	dir := setupWatchdogLogger(t)
+	defer os.RemoveAll(dir)
+	defer logger.Finalise()
+
+	initialiseStatus()
+	alerts = alert.NewManager(nil, nil, time.Hour, time.Second)
+
+	chains := map[string]string{"a": "testing", "b": "testing", "c": "bitmark"}
+	statusMutex.Lock()
+	for name, chain := range chains {
+		statusOf(name).chain = chain
+	}
+	statusMutex.Unlock()
+
+	drifts := func() []alert.Incident {
+		incidents := make([]alert.Incident, 0)
+		for _, i := range alerts.Incidents() {
+			if versionDriftCondition == i.Condition {
+				incidents = append(incidents, i)
+			}
+		}
+		return incidents
+	}
+
+	checkInfo(&node{name: "a", log: logger.New("a")}, testInfo("1.0", true))
+	checkInfo(&node{name: "c", log: logger.New("c")}, testInfo("2.0", true))
+	assert.Equal(t, 0, len(drifts()), "wrong drift across chains")
+
+	checkInfo(&node{name: "b", log: logger.New("b")}, testInfo("1.1", true))
+	incidents := drifts()
+	assert.Equal(t, 1, len(incidents), "wrong drift count")
+	assert.Equal(t, fleetOf("testing"), incidents[0].Node, "wrong drift node")
+	assert.Equal(t, "testing", incidents[0].Chain, "wrong drift chain")
+}
+
+func TestIsNormal(t *testing.T) {
+	initialiseStatus()
+	assert.True(t, isNormal("a"), "wrong unknown node")
+
+	statusMutex.Lock()
+	statusOf("a").info = testInfo("1.0", false)
+	statusMutex.Unlock()
+	assert.False(t, isNormal("a"), "wrong not normal node")
+}
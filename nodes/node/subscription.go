@@ -0,0 +1,51 @@
+package node
+
+import (
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/fault"
+)
+
+// broadcast categories configurable in node subscribe list
+const (
+	subscribeBlock       = "block"
+	subscribeHeartbeat   = "heartbeat"
+	subscribeTransaction = "transaction"
+)
+
+// subscription - broadcast categories a node expects to receive, frames are
+// filtered after they arrive, zmq subscribes whole chain since category is
+// second frame of broadcast
+type subscription map[string]struct{}
+
+var subscribeCategories = map[string][]string{
+	subscribeBlock:       {blockCmdStr},
+	subscribeHeartbeat:   {heartbeatCmdStr},
+	subscribeTransaction: {assetCmdStr, issueCmdStr, transferCmdStr},
+}
+
+// newSubscription - empty list subscribes every category
+func newSubscription(list []string) (subscription, error) {
+	if 0 == len(list) {
+		list = []string{subscribeBlock, subscribeHeartbeat, subscribeTransaction}
+	}
+
+	s := make(subscription)
+	for _, name := range list {
+		if _, ok := subscribeCategories[name]; !ok {
+			return nil, fault.InvalidSubscription
+		}
+		s[name] = struct{}{}
+	}
+	return s, nil
+}
+
+// accept - true if broadcast category belongs to subscribed names
+func (s subscription) accept(category string) bool {
+	for name := range s {
+		for _, c := range subscribeCategories[name] {
+			if c == category {
+				return true
+			}
+		}
+	}
+	return false
+}
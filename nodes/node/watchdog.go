@@ -0,0 +1,128 @@
+package node
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/db"
+)
+
+const (
+	silenceMeasurement = "heartbeat-silence"
+)
+
+// watchdog - node is silent when no heartbeat arrives before timeout, state
+// is only touched by watchdog loop
+type watchdog struct {
+	timeout time.Duration
+	beats   chan time.Time
+	last    time.Time
+	silent  bool
+}
+
+// heartbeatSilence - silence of node since last heartbeat
+type heartbeatSilence struct {
+	Last     time.Time
+	Duration time.Duration
+}
+
+// newWatchdog - nil when silence detection is disabled
+func newWatchdog(intervalSecond int, beats int) *watchdog {
+	if 0 >= intervalSecond || 0 >= beats {
+		return nil
+	}
+
+	return &watchdog{
+		timeout: time.Duration(intervalSecond*beats) * time.Second,
+		beats:   make(chan time.Time, 1),
+	}
+}
+
+// beat - heartbeat arrives, receiver is never blocked by watchdog
+func (w *watchdog) beat(at time.Time) {
+	if nil == w {
+		return
+	}
+
+	select {
+	case w.beats <- at:
+	default:
+	}
+}
+
+// expire - timeout passed without heartbeat, silence counts from last
+// heartbeat
+func (w *watchdog) expire(now time.Time) heartbeatSilence {
+	w.silent = true
+	return heartbeatSilence{
+		Last:     w.last,
+		Duration: now.Sub(w.last),
+	}
+}
+
+// arrive - heartbeat at time, silence ends when node was silent
+func (w *watchdog) arrive(at time.Time) (heartbeatSilence, bool) {
+	silence := heartbeatSilence{
+		Last:     w.last,
+		Duration: at.Sub(w.last),
+	}
+	silent := w.silent
+
+	w.last = at
+	w.silent = false
+	return silence, silent
+}
+
+// watchdogLoop - fire node silent as soon as timeout passes without
+// heartbeat, node back resolves it on next heartbeat
+func watchdogLoop(args []interface{}) {
+	if 2 != len(args) {
+		fmt.Println("watchdogLoop wrong argument length")
+		return
+	}
+	n := args[0].(Node)
+	w := args[1].(*watchdog)
+	log := n.Log()
+
+	// node never sending heartbeat is silent from start of monitor
+	w.last = time.Now()
+	timer := time.NewTimer(w.timeout)
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("terminate watchdog loop")
+			return
+
+		case at := <-w.beats:
+			silence, silent := w.arrive(at)
+			if silent {
+				back(n, silence)
+			} else if !timer.Stop() {
+				<-timer.C
+			}
+			timer.Reset(w.timeout)
+
+		case now := <-timer.C:
+			silence := w.expire(now)
+			msg := render(n.Name(), nodeSilentCondition, silence)
+			log.Warn(msg)
+			fire(n.Name(), nodeSilentCondition, msg)
+		}
+	}
+}
+
+// back - silence of node ends, node back is sent as resolution of node
+// silent, duration of silence is recorded
+func back(n Node, silence heartbeatSilence) {
+	msg := render(n.Name(), nodeBackCondition, silence)
+	n.Log().Warn(msg)
+	resolveWith(n.Name(), nodeSilentCondition, msg)
+
+	db.Add(db.InfluxData{
+		Fields:      map[string]interface{}{"value": silence.Duration.Seconds()},
+		Measurement: silenceMeasurement,
+		Tags:        map[string]string{"name": n.Name()},
+		Timing:      time.Now(),
+	})
+}
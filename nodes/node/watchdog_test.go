@@ -0,0 +1,151 @@
+package node
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bitmark-inc/logger"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/alert"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/messengers"
+	"github.com/stretchr/testify/assert"
+)
+
+type testMessenger struct {
+	sync.Mutex
+	messages []string
+}
+
+func (t *testMessenger) Send(args ...interface{}) error {
+	t.Lock()
+	defer t.Unlock()
+
+	t.messages = append(t.messages, fmt.Sprint(args[0]))
+	return nil
+}
+
+func (t *testMessenger) Valid() bool {
+	return true
+}
+
+func (t *testMessenger) sent() []string {
+	t.Lock()
+	defer t.Unlock()
+
+	return append([]string{}, t.messages...)
+}
+
+func setupWatchdogLogger(t *testing.T) string {
+	dir, _ := ioutil.TempDir("", "watchdog")
+	err := logger.Initialise(logger.Configuration{
+		Directory: dir,
+		File:      "watchdog.log",
+		Size:      1048576,
+		Count:     10,
+		Levels:    map[string]string{logger.DefaultTag: "critical"},
+	})
+	if nil != err {
+		t.Fatalf("initialise logger with error: %s", err)
+	}
+	return dir
+}
+
+func TestNewWatchdog(t *testing.T) {
+	assert.Nil(t, newWatchdog(60, 0), "wrong watchdog when disabled")
+	assert.Equal(t, 3*time.Minute, newWatchdog(60, 3).timeout, "wrong timeout")
+}
+
+func TestWatchdogBeatOfDisabled(t *testing.T) {
+	var w *watchdog
+	w.beat(time.Now())
+}
+
+func TestWatchdogBeatNotBlocked(t *testing.T) {
+	w := newWatchdog(60, 3)
+	now := time.Now()
+
+	w.beat(now)
+	w.beat(now.Add(time.Minute))
+	assert.Equal(t, now, <-w.beats, "wrong beat")
+}
+
+func TestWatchdogSilenceAndBack(t *testing.T) {
+	w := newWatchdog(60, 3)
+	now := time.Now()
+
+	_, silent := w.arrive(now)
+	assert.False(t, silent, "wrong silent of regular heartbeat")
+
+	silence := w.expire(now.Add(3 * time.Minute))
+	assert.Equal(t, now, silence.Last, "wrong last heartbeat")
+	assert.Equal(t, 3*time.Minute, silence.Duration, "wrong silent duration")
+
+	silence, silent = w.arrive(now.Add(5 * time.Minute))
+	assert.True(t, silent, "wrong node not back")
+	assert.Equal(t, 5*time.Minute, silence.Duration, "wrong silence duration")
+
+	_, silent = w.arrive(now.Add(6 * time.Minute))
+	assert.False(t, silent, "wrong silent after back")
+}
+
+func TestWatchdogMessages(t *testing.T) {
+	initialiseStatus()
+	silence := heartbeatSilence{Duration: 5*time.Minute + time.Millisecond}
+
+	assert.Equal(t, "no heartbeat for 5m0s", render("a", nodeSilentCondition, silence), "wrong silent message")
+	assert.Equal(t, "heartbeat back after 5m0s of silence", render("a", nodeBackCondition, silence), "wrong back message")
+	assert.Equal(t, messengers.Critical, severity(nodeSilentCondition), "wrong silent severity")
+}
+
+func TestWatchdogLoop(t *testing.T) {
+	dir := setupWatchdogLogger(t)
+	defer os.RemoveAll(dir)
+	defer logger.Finalise()
+
+	initialiseStatus()
+	messenger := &testMessenger{}
+	alerts = alert.NewManager(map[string]messengers.Messenger{"test": messenger}, nil, time.Hour, time.Second)
+
+	c, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ctx = c
+
+	n := &node{name: "a", log: logger.New("a")}
+	w := &watchdog{timeout: 50 * time.Millisecond, beats: make(chan time.Time, 1)}
+	done := make(chan struct{})
+	go func() {
+		watchdogLoop([]interface{}{n, w})
+		close(done)
+	}()
+
+	silent := func() bool {
+		for _, i := range alerts.Incidents() {
+			if "a" == i.Node && nodeSilentCondition == i.Condition {
+				return true
+			}
+		}
+		return false
+	}
+	assert.Eventually(t, silent, time.Second, 10*time.Millisecond, "wrong node not silent")
+
+	w.beat(time.Now())
+	assert.Eventually(t, func() bool { return !silent() }, time.Second, 10*time.Millisecond, "wrong node not back")
+
+	alerts.Flush()
+	sent := messenger.sent()
+	assert.Equal(t, 2, len(sent), "wrong message count")
+	assert.Contains(t, sent[0], "a no heartbeat for", "wrong silent message")
+	assert.Contains(t, sent[1], "a node-silent resolved after", "wrong resolved message")
+	assert.Contains(t, sent[1], "heartbeat back after", "wrong back message")
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Error("watchdog loop not terminated")
+	}
+}
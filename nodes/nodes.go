@@ -9,6 +9,7 @@ import (
 	"github.com/jamieabc/bitmarkd-broadcast-monitor/db"
 
 	"github.com/bitmark-inc/logger"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/bot"
 	"github.com/jamieabc/bitmarkd-broadcast-monitor/configuration"
 	"github.com/jamieabc/bitmarkd-broadcast-monitor/nodes/node"
 )
@@ -38,9 +39,21 @@ func Initialise(configs configuration.Configuration) (Nodes, error) {
 	t := tasks.NewTasks(done, cancel)
 
 	nodeConfigs := configs.NodesConfig()
-	node.Initialise(configs, t, ctx)
+	if err := node.Initialise(configs, t, ctx); nil != err {
+		log.Errorf("initialise node with error: %s", err)
+		cancel()
+		return nil, err
+	}
 	t.Go(db.Start, ctx.Done())
 
+	if captureConfig := configs.CaptureConfig(); captureConfig.Enable {
+		if err := node.InitialiseCapture(captureConfig); nil != err {
+			log.Errorf("initialise capture with error: %s", err)
+			cancel()
+			return nil, err
+		}
+	}
+
 	for idx, c := range nodeConfigs {
 		n, err := node.NewNode(c, idx)
 		if nil != err {
@@ -49,6 +62,10 @@ func Initialise(configs configuration.Configuration) (Nodes, error) {
 		ns = append(ns, n)
 	}
 
+	if slackConfig := configs.SlackConfig(); slackConfig.Bot {
+		t.Go(bot.New(slackConfig, node.NewQuerier(ns)).Loop, ctx.Done())
+	}
+
 	return &nodes{
 		cancel:  cancel,
 		ctx:     ctx,
@@ -76,5 +93,6 @@ func (n *nodes) StopMonitor() {
 	n.log.Infof("stop monitor")
 	go n.tasks.Done()
 	<-n.done
+	node.Finalise()
 	n.log.Flush()
 }
@@ -0,0 +1,182 @@
+package nodes_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bitmark-inc/logger"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/capture"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/configuration"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/fakebitmarkd"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/nodes"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testingDirName = "testing"
+	testChain      = "testing"
+	testNodeName   = "fake"
+	floodCount     = 1000
+
+	// capture is flushed every second, watchdog fires after 2 silent seconds
+	waitTimeout  = 10 * time.Second
+	pollInterval = 100 * time.Millisecond
+)
+
+var captureDir = filepath.Join(testingDirName, "capture")
+
+func setupTestLogger() {
+	removeFiles()
+	_ = os.Mkdir(testingDirName, 0700)
+
+	_ = logger.Initialise(logger.Configuration{
+		Directory: testingDirName,
+		File:      "testing.log",
+		Size:      1048576,
+		Count:     10,
+		Console:   false,
+		Levels: map[string]string{
+			logger.DefaultTag: "critical",
+		},
+	})
+}
+
+func removeFiles() {
+	_ = os.RemoveAll(testingDirName)
+}
+
+func setupTestConfig(t *testing.T, server *fakebitmarkd.Server) configuration.Configuration {
+	public, private, err := fakebitmarkd.NewKeys()
+	if nil != err {
+		t.Fatalf("new keys with error: %s", err)
+	}
+
+	content := fmt.Sprintf(`
+local M = {}
+M.nodes = {
+  {
+    ip = "127.0.0.1",
+    broadcast_port = "%s",
+    command_port = "%s",
+    public_key = "%s",
+    chain = "%s",
+    name = "%s",
+  },
+}
+M.keys = {
+  public = "%s",
+  private = "%s",
+}
+M.heartbeat_interval_second = 1
+M.heartbeat_silence_beats = 2
+M.capture = {
+  enable = true,
+  directory = "%s",
+  file = "test",
+  size = 10485760,
+  count = 10,
+}
+return M
+`, server.BroadcastPort(), server.CommandPort(), server.PublicKey(), testChain, testNodeName, public, private, captureDir)
+
+	file := filepath.Join(testingDirName, "test.conf")
+	_ = ioutil.WriteFile(file, []byte(content), 0600)
+
+	config, err := configuration.Parse(file)
+	if nil != err {
+		t.Fatalf("parse config with error: %s", err)
+	}
+	return config
+}
+
+// capturedCategories - count of frames of each category, trailing frame
+// not flushed yet is not counted while capture is still written
+func capturedCategories(t *testing.T) map[string]int {
+	r, err := capture.NewReader(captureDir)
+	if nil != err {
+		t.Fatalf("new capture reader with error: %s", err)
+	}
+	defer r.Close()
+
+	categories := make(map[string]int)
+	for {
+		f, err := r.Next()
+		if nil != err {
+			return categories
+		}
+		assert.Equal(t, testNodeName, f.Name, "wrong node name")
+		categories[string(f.Data[1])]++
+	}
+}
+
+func TestMonitorWithFakeBitmarkd(t *testing.T) {
+	setupTestLogger()
+	defer removeFiles()
+
+	server, err := fakebitmarkd.NewServer(testChain)
+	if nil != err {
+		t.Fatalf("new server with error: %s", err)
+	}
+	defer server.Close()
+
+	n, err := nodes.Initialise(setupTestConfig(t, server))
+	if nil != err {
+		t.Fatalf("initialise nodes with error: %s", err)
+	}
+	go n.Monitor()
+	q := nodes.Querier(n)
+
+	// broadcast before subscription is ready is dropped, heartbeat is
+	// repeated until one is captured
+	assert.Eventually(t, func() bool {
+		_ = server.Heartbeat()
+		return 0 < capturedCategories(t)["heart"]
+	}, waitTimeout, pollInterval, "no heartbeat captured")
+	hearts := capturedCategories(t)["heart"]
+
+	err = server.Play([]fakebitmarkd.Event{
+		{Category: "heart", Data: [][]byte{[]byte("beat")}},
+		{Delay: 10 * time.Millisecond, Category: "heart", Data: [][]byte{[]byte("beat")}},
+	})
+	assert.Nil(t, err, "wrong play")
+
+	_, err = server.AddBlock(uint64(100), time.Now())
+	assert.Nil(t, err, "wrong add block")
+
+	err = server.Flood("transfer", []byte("not a transaction"), 0, floodCount)
+	assert.Nil(t, err, "wrong flood")
+
+	assert.Eventually(t, func() bool {
+		categories := capturedCategories(t)
+		return hearts+2 <= categories["heart"] && 1 == categories["block"] && floodCount == categories["transfer"]
+	}, waitTimeout, pollInterval, "broadcasts not captured")
+
+	nodes.Check(n)
+	status, err := q.Node(testNodeName)
+	assert.Nil(t, err, "wrong node")
+	assert.Contains(t, status, "block: receive 1 blocks", "wrong block summary")
+	assert.Contains(t, status, "heartbeat: earliest received time", "wrong heartbeat summary")
+	assert.Contains(t, status, "transaction: ", "wrong transaction summary")
+
+	// heartbeats stopped, watchdog opens incident and next heartbeat
+	// resolves it
+	assert.Eventually(t, func() bool {
+		return strings.Contains(q.Fleet(), "incidents node-silent")
+	}, waitTimeout, pollInterval, "node silent not alerted")
+
+	assert.Eventually(t, func() bool {
+		_ = server.Heartbeat()
+		return !strings.Contains(q.Fleet(), "node-silent")
+	}, waitTimeout, pollInterval, "node silent not resolved")
+
+	n.StopMonitor()
+
+	categories := capturedCategories(t)
+	assert.Equal(t, 1, categories["block"], "wrong block count")
+	assert.Equal(t, floodCount, categories["transfer"], "wrong transfer count")
+}
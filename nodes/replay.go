@@ -0,0 +1,180 @@
+package nodes
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/bitmark-inc/logger"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/capture"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/configuration"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/nodes/node"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/recorder"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/tasks"
+)
+
+const (
+	// same as checker loop interval of live monitor
+	replayCheckInterval = 2 * time.Minute
+)
+
+type replay struct {
+	*nodes
+	current       time.Time
+	currentMux    sync.RWMutex
+	groupInterval time.Duration
+	nodeMap       map[string]node.Node
+	reader        capture.Reader
+	speed         float64
+}
+
+// InitialiseReplay - initialise nodes fed by captured broadcasts instead of network
+// speed 1 replays in real time, larger is faster, 0 replays without waiting
+// alerts are written to log instead of sent, and follow capture time
+func InitialiseReplay(configs configuration.Configuration, reader capture.Reader, speed float64) (Nodes, error) {
+	log := logger.New("replay")
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	t := tasks.NewTasks(done, cancel)
+
+	r := &replay{
+		nodes: &nodes{
+			cancel: cancel,
+			ctx:    ctx,
+			done:   done,
+			log:    log,
+			tasks:  t,
+		},
+		groupInterval: time.Duration(configs.AlertConfig().GroupSecond) * time.Second,
+		nodeMap:       make(map[string]node.Node),
+		reader:        reader,
+		speed:         speed,
+	}
+
+	if err := node.InitialiseReplay(configs, t, ctx, r.now); nil != err {
+		log.Errorf("initialise node with error: %s", err)
+		cancel()
+		return nil, err
+	}
+
+	for idx, c := range configs.NodesConfig() {
+		n, err := node.NewReplayNode(c, idx)
+		if nil != err {
+			cancel()
+			return nil, err
+		}
+		r.nodeArr = append(r.nodeArr, n)
+		r.nodeMap[n.Name()] = n
+	}
+
+	recorder.SetTimeSource(r.now)
+
+	return r, nil
+}
+
+// Monitor - replay captured broadcasts, returns when capture is finished
+func (r *replay) Monitor() {
+	r.log.Info("start replay")
+	for _, n := range r.nodeArr {
+		r.tasks.Go(n.Monitor)
+	}
+
+	r.play()
+	r.check()
+	node.FlushAlerts()
+
+	r.log.Info("finish replay")
+	r.log.Flush()
+}
+
+func (r *replay) play() {
+	var previous, nextCheck, nextFlush time.Time
+
+	for {
+		f, err := r.reader.Next()
+		if io.EOF == err {
+			return
+		}
+		if nil != err {
+			r.log.Errorf("read capture with error: %s", err)
+			return
+		}
+
+		n, ok := r.nodeMap[f.Name]
+		if !ok {
+			r.log.Debugf("skip frame of unknown node %s", f.Name)
+			continue
+		}
+
+		if !previous.IsZero() && !r.wait(f.ReceivedAt.Sub(previous)) {
+			r.log.Info("replay interrupted")
+			return
+		}
+		previous = f.ReceivedAt
+
+		// run checks on capture time as checker loop does on wall clock
+		if nextCheck.IsZero() {
+			nextCheck = f.ReceivedAt.Add(replayCheckInterval)
+		}
+		for !f.ReceivedAt.Before(nextCheck) {
+			r.setNow(nextCheck)
+			r.check()
+			nextCheck = nextCheck.Add(replayCheckInterval)
+		}
+
+		// alerts are grouped on capture time as alert manager loop does
+		if nextFlush.IsZero() {
+			nextFlush = f.ReceivedAt.Add(r.groupInterval)
+		}
+		if !f.ReceivedAt.Before(nextFlush) {
+			r.setNow(f.ReceivedAt)
+			node.FlushAlerts()
+			nextFlush = f.ReceivedAt.Add(r.groupInterval)
+		}
+
+		r.setNow(f.ReceivedAt)
+		n.Process(f.Data, f.ReceivedAt)
+	}
+}
+
+// wait - wait scaled capture interval, false if replay is stopped
+func (r *replay) wait(interval time.Duration) bool {
+	if 0 >= r.speed || 0 >= interval {
+		select {
+		case <-r.ctx.Done():
+			return false
+		default:
+			return true
+		}
+	}
+
+	select {
+	case <-r.ctx.Done():
+		return false
+	case <-time.After(time.Duration(float64(interval) / r.speed)):
+		return true
+	}
+}
+
+func (r *replay) check() {
+	for _, n := range r.nodeArr {
+		n.Check()
+	}
+}
+
+func (r *replay) now() time.Time {
+	r.currentMux.RLock()
+	defer r.currentMux.RUnlock()
+
+	if r.current.IsZero() {
+		return time.Now()
+	}
+	return r.current
+}
+
+func (r *replay) setNow(t time.Time) {
+	r.currentMux.Lock()
+	r.current = t
+	r.currentMux.Unlock()
+}
@@ -0,0 +1,127 @@
+package nodes_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/capture"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/configuration"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/fakebitmarkd"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/nodes"
+	"github.com/stretchr/testify/assert"
+)
+
+// setupReplayConfig - nodes a and b, alert when no broadcast for 5 minutes
+func setupReplayConfig(t *testing.T) configuration.Configuration {
+	public, private, err := fakebitmarkd.NewKeys()
+	if nil != err {
+		t.Fatalf("new keys with error: %s", err)
+	}
+
+	content := fmt.Sprintf(`
+local M = {}
+M.nodes = {
+  {
+    ip = "127.0.0.1",
+    broadcast_port = "2135",
+    public_key = "%s",
+    chain = "%s",
+    name = "a",
+  },
+  {
+    ip = "127.0.0.1",
+    broadcast_port = "2145",
+    public_key = "%s",
+    chain = "%s",
+    name = "b",
+  },
+}
+M.keys = {
+  public = "%s",
+  private = "%s",
+}
+M.alerts = {
+  {
+    name = "disconnected",
+    metric = "disconnect-time",
+    comparison = ">=",
+    threshold = 5,
+  },
+}
+return M
+`, public, testChain, public, testChain, public, private)
+
+	file := filepath.Join(testingDirName, "replay.conf")
+	_ = ioutil.WriteFile(file, []byte(content), 0600)
+
+	config, err := configuration.Parse(file)
+	if nil != err {
+		t.Fatalf("parse config with error: %s", err)
+	}
+	return config
+}
+
+// writeHeartbeats - heartbeat of node every minute from start for minutes
+func writeHeartbeats(t *testing.T, w capture.Writer, name string, start time.Time, minutes int) {
+	for i := 0; i <= minutes; i++ {
+		err := w.Write(capture.Frame{
+			Name:       name,
+			ReceivedAt: start.Add(time.Duration(i) * time.Minute),
+			Data:       [][]byte{[]byte(testChain), []byte("heart"), []byte("beat")},
+		})
+		if nil != err {
+			t.Fatalf("write capture with error: %s", err)
+		}
+	}
+}
+
+func TestReplayChecksDisconnect(t *testing.T) {
+	setupTestLogger()
+	defer removeFiles()
+
+	w, err := capture.NewWriter(configuration.CaptureConfig{
+		Enable:    true,
+		Directory: captureDir,
+		File:      "replay",
+		Size:      10485760,
+		Count:     10,
+	})
+	if nil != err {
+		t.Fatalf("new capture writer with error: %s", err)
+	}
+
+	// a stops broadcasting after 1 minute, b keeps on for 10 minutes
+	start := time.Now().Add(-time.Hour)
+	writeHeartbeats(t, w, "a", start, 1)
+	writeHeartbeats(t, w, "b", start.Add(2*time.Minute), 8)
+	_ = w.Close()
+
+	r, err := capture.NewReader(captureDir)
+	if nil != err {
+		t.Fatalf("new capture reader with error: %s", err)
+	}
+	defer r.Close()
+
+	n, err := nodes.InitialiseReplay(setupReplayConfig(t), r, 0)
+	if nil != err {
+		t.Fatalf("initialise replay with error: %s", err)
+	}
+	n.Monitor()
+	defer n.StopMonitor()
+
+	fleet := nodes.Querier(n).Fleet()
+	for _, line := range strings.Split(fleet, "\n") {
+		if strings.HasPrefix(line, "a: ") {
+			assert.Contains(t, line, "incidents disconnected", "wrong disconnect of silent node")
+		}
+		if strings.HasPrefix(line, "b: ") {
+			assert.NotContains(t, line, "disconnected", "wrong disconnect of broadcasting node")
+		}
+	}
+	assert.Contains(t, fleet, "a: ", "wrong fleet")
+	assert.Contains(t, fleet, "b: ", "wrong fleet")
+}
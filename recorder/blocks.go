@@ -10,8 +10,7 @@ import (
 )
 
 const (
-	dataLength          = 100
-	longConfirmInterval = 30 * time.Minute
+	dataLength = 100
 )
 
 // BlockData - data of a block
@@ -33,14 +32,6 @@ type Fork struct {
 	ExpiredAt time.Time
 }
 
-// LongConfirm - structure to record long confirmation
-type LongConfirm struct {
-	BlockNumber uint64
-	ExpiredAt   time.Time
-	Period      time.Duration
-	Reported    bool
-}
-
 type blocks struct {
 	sync.Mutex
 	data           [dataLength]BlockData
@@ -48,18 +39,21 @@ type blocks struct {
 	latestBlock    BlockData
 	forkInProgress bool
 	forks          []Fork
-	longConfirms   []LongConfirm
+	maxConfirm     time.Duration
 	id             int
 }
 
 // Add - add BlockData item
 func (b *blocks) Add(t time.Time, args ...interface{}) {
+	b.Lock()
+	defer b.Unlock()
+
 	nextBlock := args[0].(BlockData)
 	nextBlock.ReceivedTime = t
 	processFork(b, nextBlock)
 	b.addBlock(nextBlock)
 	b.updateLatestBlock(nextBlock)
-	b.updateLongConfirmPeriodInfo(nextBlock)
+	b.updateMaxConfirm(nextBlock)
 }
 
 func processFork(b *blocks, nextBlock BlockData) {
@@ -130,14 +124,11 @@ func (b *blocks) updateLatestBlock(nextBlock BlockData) {
 	}
 }
 
-func (b *blocks) updateLongConfirmPeriodInfo(nextBlock BlockData) {
+// updateMaxConfirm - long confirm is decided by confirm time rules
+func (b *blocks) updateMaxConfirm(nextBlock BlockData) {
 	confirmInterval := nextBlock.ReceivedTime.Sub(nextBlock.GenerateTime)
-	if confirmInterval >= longConfirmInterval {
-		b.longConfirms = append(b.longConfirms, LongConfirm{
-			BlockNumber: nextBlock.Number,
-			ExpiredAt:   nextBlock.ReceivedTime.Add(expiredTimeInterval),
-			Period:      confirmInterval,
-		})
+	if confirmInterval > b.maxConfirm {
+		b.maxConfirm = confirmInterval
 	}
 }
 
@@ -165,18 +156,23 @@ loop:
 			break loop
 
 		case <-timer.C:
-			now := time.Now()
-			b.Lock()
-			cleanupExpiredBlocks(b, now)
-			cleanupExpiredForks(b, now)
-			cleanupExpiredLongConfirms(b, now)
-			b.Unlock()
+			b.RemoveExpired()
 			timer.Reset(expiredTimeInterval)
 		}
 	}
 	fmt.Println("terminate blocks PeriodicRemove")
 }
 
+// RemoveExpired - remove outdated item now
+func (b *blocks) RemoveExpired() {
+	now := currentTime()
+	b.Lock()
+	defer b.Unlock()
+
+	cleanupExpiredBlocks(b, now)
+	cleanupExpiredForks(b, now)
+}
+
 func cleanupExpiredBlocks(b *blocks, now time.Time) {
 	expiredTime := now.Add(-1 * expiredTimeInterval)
 	startIdx, endIdx := indexNotFound, indexNotFound
@@ -220,26 +216,24 @@ func cleanupExpiredForks(b *blocks, now time.Time) {
 	}
 }
 
-func cleanupExpiredLongConfirms(b *blocks, now time.Time) {
-	var startIdx int
-	for i, c := range b.longConfirms {
-		if now.Before(c.ExpiredAt) {
-			startIdx = i
-		}
-	}
-	if 0 != startIdx {
-		b.longConfirms = b.longConfirms[startIdx:]
-	}
-}
-
-// Summary - summarize blocks stat
+// Summary - summarize blocks stat, max confirm is reset for next summary
 func (b *blocks) Summary() SummaryOutput {
+	b.Lock()
+	defer b.Unlock()
+
 	duration, blockCount, missingBlocks := summarize(b)
+
+	forks := make([]Fork, len(b.forks))
+	copy(forks, b.forks)
+
+	maxConfirm := b.maxConfirm
+	b.maxConfirm = time.Duration(0)
+
 	return &BlocksSummary{
 		BlockCount:    blockCount,
 		Duration:      duration,
-		Forks:         b.forks,
-		LongConfirms:  b.longConfirms,
+		Forks:         forks,
+		MaxConfirm:    maxConfirm,
 		MissingBlocks: missingBlocks,
 	}
 }
@@ -251,7 +245,7 @@ func summarize(b *blocks) (time.Duration, uint64, []uint64) {
 
 	sorted := sortArray(b)
 	count, missing := missingBlocks(sorted)
-	return time.Now().Sub(sorted[0].ReceivedTime), uint64(count), missing
+	return currentTime().Sub(sorted[0].ReceivedTime), uint64(count), missing
 }
 
 func sortArray(b *blocks) []BlockData {
@@ -312,47 +306,36 @@ type BlocksSummary struct {
 	BlockCount    uint64
 	Duration      time.Duration
 	Forks         []Fork
-	LongConfirms  []LongConfirm // confirm time longer than 30 minutes
+	MaxConfirm    time.Duration // longest confirm time since last summary
 	MissingBlocks []uint64
 }
 
+// MaxForkDepth - blocks of deepest fork
+func (b *BlocksSummary) MaxForkDepth() uint64 {
+	var max uint64
+	for _, f := range b.Forks {
+		if depth := f.End - f.Begin + 1; f.End >= f.Begin && depth > max {
+			max = depth
+		}
+	}
+	return max
+}
+
 func (b *BlocksSummary) String() string {
 	return fmt.Sprintf(
-		"receive %d blolcks in %s, forks: %d, long confirms: %d, missing blocks: %v\n%s\n%s",
+		"receive %d blocks in %s, forks: %d, max confirm: %s, missing blocks: %v\n%s",
 		b.BlockCount,
 		b.Duration,
 		len(b.Forks),
-		len(b.LongConfirms),
+		b.MaxConfirm,
 		b.MissingBlocks,
 		forkInfo(b.Forks),
-		confirmInfo(b.LongConfirms),
 	)
 }
 
-// Valid - any long confirmation, fork, or block not continuous that has not reported
+// Valid - no fork or block not continuous, alerts are decided by rules
 func (b *BlocksSummary) Valid() bool {
-	var reported []LongConfirm
-	if 0 == len(b.LongConfirms) && 0 == len(b.Forks) && 0 == len(b.MissingBlocks) {
-		return true
-	}
-
-	if 0 < len(b.MissingBlocks) {
-		return false
-	}
-
-	for _, c := range b.LongConfirms {
-		if !c.Reported {
-			c.Reported = true
-			reported = append(reported, c)
-		}
-	}
-
-	if 0 < len(reported) || 0 < len(b.Forks) {
-		b.LongConfirms = reported
-		return false
-	}
-
-	return true
+	return 0 == len(b.Forks) && 0 == len(b.MissingBlocks)
 }
 
 func forkInfo(forks []Fork) string {
@@ -367,23 +350,10 @@ func forkInfo(forks []Fork) string {
 	return ""
 }
 
-func confirmInfo(longConfirms []LongConfirm) string {
-	if 0 < len(longConfirms) {
-		var str strings.Builder
-		str.WriteString("long confirms: ")
-		for _, c := range longConfirms {
-			str.WriteString(fmt.Sprintf("block %d takes %d ", c.BlockNumber, c.Period))
-		}
-		return str.String()
-	}
-	return ""
-}
-
 // NewBlock - new blocks data structure
 func NewBlock() Recorder {
 	return &blocks{
-		earliest:     time.Now(),
-		forks:        make([]Fork, 0),
-		longConfirms: make([]LongConfirm, 0),
+		earliest: currentTime(),
+		forks:    make([]Fork, 0),
 	}
 }
@@ -199,6 +199,7 @@ func TestSummaryWhenForkMultipleBlocks(t *testing.T) {
 	assert.Equal(t, 1, len(summary.Forks), "wrong Fork count")
 	assert.Equal(t, blockNumber+3, summary.Forks[0].Begin, "wrong Fork start")
 	assert.Equal(t, blockNumber+4, summary.Forks[0].End, "wrong Fork end")
+	assert.Equal(t, uint64(2), summary.MaxForkDepth(), "wrong max fork depth")
 }
 
 func TestSummaryWhenForkMultipleBlocksMultipleTimes(t *testing.T) {
@@ -460,63 +461,10 @@ func TestSummaryWhenLongConfirmTime(t *testing.T) {
 
 	summary := b.Summary().(*recorder.BlocksSummary)
 
-	assert.Equal(t, 2, len(summary.LongConfirms), "wrong long confirm count")
-	assert.Equal(t, 2*time.Hour, summary.LongConfirms[0].Period, "wrong first long confirm period")
-	assert.Equal(t, 1*time.Hour, summary.LongConfirms[1].Period, "wrong second long confirm period")
-}
-
-func TestSummaryWhenLongConfirmRecycled(t *testing.T) {
-	ctl, mock := setupTestClock(t)
-	defer ctl.Finish()
-	mock.EXPECT().NewTimer(gomock.Any()).Return(time.NewTimer(1)).Times(1)
-
-	now := time.Now()
-	b := recorder.NewBlock()
-	threeHourBefore := now.Add(-3 * time.Hour)
-	oneHourBefore := now.Add(-1 * time.Hour)
-	blockNumber := uint64(1000)
-
-	b.Add(now, recorder.BlockData{
-		Hash:         "1",
-		Number:       blockNumber,
-		GenerateTime: threeHourBefore,
-	})
-	b.Add(now, recorder.BlockData{
-		Hash:         "2",
-		Number:       blockNumber + 1,
-		GenerateTime: threeHourBefore,
-	})
-	b.Add(now, recorder.BlockData{
-		Hash:         "3",
-		Number:       blockNumber + 2,
-		GenerateTime: oneHourBefore,
-	})
-
-	go b.PeriodicRemove([]interface{}{mock, ctx.Done()})
-	<-time.After(10 * time.Millisecond)
-
-	summary := b.Summary().(*recorder.BlocksSummary)
-	assert.Equal(t, 1, len(summary.LongConfirms), "wrong long confirm count")
-	assert.Equal(t, blockNumber+2, summary.LongConfirms[0].BlockNumber, "wrong long confirm block number")
-}
-
-func TestBlockSummaryValidWhenInvalidOfLongConfirms(t *testing.T) {
-	s := recorder.BlocksSummary{
-		BlockCount: 10,
-		Duration:   time.Hour,
-		Forks:      []recorder.Fork{},
-		LongConfirms: []recorder.LongConfirm{
-			{
-				BlockNumber: 1000,
-				ExpiredAt:   time.Time{},
-				Period:      3 * time.Hour,
-				Reported:    false,
-			},
-		},
-	}
+	assert.Equal(t, 2*time.Hour, summary.MaxConfirm, "wrong max confirm")
 
-	assert.Equal(t, false, s.Valid(), "wrong valid first time")
-	assert.Equal(t, true, s.Valid(), "wrong valid second time")
+	summary = b.Summary().(*recorder.BlocksSummary)
+	assert.Equal(t, time.Duration(0), summary.MaxConfirm, "wrong max confirm after summary")
 }
 
 func TestBlockSummaryValidWhenInvalidForks(t *testing.T) {
@@ -530,7 +478,6 @@ func TestBlockSummaryValidWhenInvalidForks(t *testing.T) {
 				ExpiredAt: time.Time{},
 			},
 		},
-		LongConfirms: []recorder.LongConfirm{},
 	}
 
 	assert.Equal(t, false, s.Valid(), "wrong valid forks")
@@ -541,7 +488,6 @@ func TestBlockSummaryValidWhenInvalidBlockContinuity(t *testing.T) {
 		BlockCount:    10,
 		Duration:      time.Hour,
 		Forks:         []recorder.Fork{},
-		LongConfirms:  []recorder.LongConfirm{},
 		MissingBlocks: []uint64{uint64(1000), uint64(1001)},
 	}
 
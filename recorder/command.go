@@ -0,0 +1,197 @@
+package recorder
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/fault"
+)
+
+const (
+	minCommandAvailability = 0.9
+)
+
+// CommandResult - round trip of a command port request
+type CommandResult struct {
+	Command string
+	Latency time.Duration
+	Err     error
+}
+
+// CommandStat - round trips of a command since last summary
+type CommandStat struct {
+	Command      string
+	Success      int
+	NotFound     int
+	Failure      int
+	Timeout      int
+	Cancelled    int
+	TotalLatency time.Duration
+	MaxLatency   time.Duration
+}
+
+// Total - count of round trips, cancelled requests are stopped by monitor
+// and not counted
+func (c CommandStat) Total() int {
+	return c.Answered() + c.Failure + c.Timeout
+}
+
+// Answered - count of round trips node replied, not found reply is a
+// valid answer of command port
+func (c CommandStat) Answered() int {
+	return c.Success + c.NotFound
+}
+
+// Availability - ratio of answered round trips, 1 when nothing is sent
+func (c CommandStat) Availability() float64 {
+	if 0 == c.Total() {
+		return float64(1)
+	}
+	return float64(c.Answered()) / float64(c.Total())
+}
+
+// AverageLatency - average latency of answered round trips
+func (c CommandStat) AverageLatency() time.Duration {
+	if 0 == c.Answered() {
+		return time.Duration(0)
+	}
+	return c.TotalLatency / time.Duration(c.Answered())
+}
+
+func (c *CommandStat) latency(l time.Duration) {
+	c.TotalLatency += l
+	if l > c.MaxLatency {
+		c.MaxLatency = l
+	}
+}
+
+type commands struct {
+	sync.Mutex
+	stats map[string]*CommandStat
+}
+
+// CommandSummary - round trips of each command since last summary
+type CommandSummary struct {
+	Stats []CommandStat
+}
+
+func (c *CommandSummary) String() string {
+	if 0 == len(c.Stats) {
+		return "no command sent"
+	}
+
+	parts := make([]string, 0, len(c.Stats))
+	for _, s := range c.Stats {
+		parts = append(parts, fmt.Sprintf(
+			"%s success %d, not found %d, failure %d, timeout %d, cancelled %d, average latency %s, max latency %s",
+			s.Command,
+			s.Success,
+			s.NotFound,
+			s.Failure,
+			s.Timeout,
+			s.Cancelled,
+			s.AverageLatency(),
+			s.MaxLatency,
+		))
+	}
+	return fmt.Sprintf("availability %.2f%%, %s", c.Availability()*100, strings.Join(parts, "; "))
+}
+
+// Valid - availability of command port is high enough
+func (c *CommandSummary) Valid() bool {
+	return c.Availability() >= minCommandAvailability
+}
+
+// Availability - ratio of successful round trips of all commands
+func (c *CommandSummary) Availability() float64 {
+	var total CommandStat
+	for _, s := range c.Stats {
+		total.Success += s.Success
+		total.NotFound += s.NotFound
+		total.Failure += s.Failure
+		total.Timeout += s.Timeout
+	}
+	return total.Availability()
+}
+
+// Add - add CommandResult
+func (c *commands) Add(_ time.Time, args ...interface{}) {
+	if 0 == len(args) {
+		return
+	}
+
+	result, ok := args[0].(CommandResult)
+	if !ok {
+		return
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	s, ok := c.stats[result.Command]
+	if !ok {
+		s = &CommandStat{Command: result.Command}
+		c.stats[result.Command] = s
+	}
+
+	switch result.Err {
+	case nil:
+		s.Success++
+		s.latency(result.Latency)
+
+	case fault.RecordNotFound:
+		s.NotFound++
+		s.latency(result.Latency)
+
+	case fault.RequestTimeout:
+		s.Timeout++
+
+	case context.Canceled:
+		s.Cancelled++
+
+	default:
+		s.Failure++
+	}
+}
+
+// PeriodicRemove - nothing expires, stats are reset by summary
+func (c *commands) PeriodicRemove(args []interface{}) {
+	if 2 != len(args) {
+		fmt.Println("commands PeriodicRemove wrong arguments length")
+		return
+	}
+	shutdown := args[1].(<-chan struct{})
+	<-shutdown
+	fmt.Println("terminate commands PeriodicRemove")
+}
+
+// RemoveExpired - nothing expires
+func (c *commands) RemoveExpired() {}
+
+// Summary - round trips since last summary
+func (c *commands) Summary() SummaryOutput {
+	c.Lock()
+	defer c.Unlock()
+
+	stats := make([]CommandStat, 0, len(c.stats))
+	for _, s := range c.stats {
+		stats = append(stats, *s)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Command < stats[j].Command
+	})
+	c.stats = make(map[string]*CommandStat)
+
+	return &CommandSummary{Stats: stats}
+}
+
+// NewCommand - new command port round trip recorder
+func NewCommand() Recorder {
+	return &commands{
+		stats: make(map[string]*CommandStat),
+	}
+}
@@ -0,0 +1,70 @@
+package recorder_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/fault"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/recorder"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommandSummary(t *testing.T) {
+	r := recorder.NewCommand()
+	now := time.Now()
+
+	r.Add(now, recorder.CommandResult{Command: "info", Latency: 10 * time.Millisecond})
+	r.Add(now, recorder.CommandResult{Command: "info", Latency: 30 * time.Millisecond})
+	r.Add(now, recorder.CommandResult{Command: "info", Err: fault.RequestTimeout})
+	r.Add(now, recorder.CommandResult{Command: "height", Err: fmt.Errorf("error")})
+
+	s := r.Summary().(*recorder.CommandSummary)
+	assert.Equal(t, 2, len(s.Stats), "wrong command count")
+
+	height := s.Stats[0]
+	assert.Equal(t, "height", height.Command, "wrong command order")
+	assert.Equal(t, 1, height.Failure, "wrong failure count")
+	assert.Equal(t, float64(0), height.Availability(), "wrong availability")
+
+	info := s.Stats[1]
+	assert.Equal(t, 2, info.Success, "wrong success count")
+	assert.Equal(t, 1, info.Timeout, "wrong timeout count")
+	assert.Equal(t, 20*time.Millisecond, info.AverageLatency(), "wrong average latency")
+	assert.Equal(t, 30*time.Millisecond, info.MaxLatency, "wrong max latency")
+
+	assert.Equal(t, 0.5, s.Availability(), "wrong total availability")
+	assert.False(t, s.Valid(), "wrong valid")
+}
+
+func TestCommandSummaryWhenNothingSent(t *testing.T) {
+	r := recorder.NewCommand()
+	r.Add(time.Now(), recorder.CommandResult{Command: "info"})
+	_ = r.Summary()
+
+	s := r.Summary().(*recorder.CommandSummary)
+	assert.Equal(t, 0, len(s.Stats), "wrong stats not reset")
+	assert.Equal(t, float64(1), s.Availability(), "wrong availability")
+	assert.True(t, s.Valid(), "wrong valid")
+}
+
+func TestCommandSummaryWhenNotFoundOrCancelled(t *testing.T) {
+	r := recorder.NewCommand()
+	now := time.Now()
+
+	r.Add(now, recorder.CommandResult{Command: "block", Latency: 10 * time.Millisecond})
+	r.Add(now, recorder.CommandResult{Command: "block", Latency: 30 * time.Millisecond, Err: fault.RecordNotFound})
+	r.Add(now, recorder.CommandResult{Command: "block", Err: context.Canceled})
+
+	s := r.Summary().(*recorder.CommandSummary)
+	block := s.Stats[0]
+	assert.Equal(t, 1, block.Success, "wrong success count")
+	assert.Equal(t, 1, block.NotFound, "wrong not found count")
+	assert.Equal(t, 1, block.Cancelled, "wrong cancelled count")
+	assert.Equal(t, 0, block.Failure, "wrong failure count")
+	assert.Equal(t, 2, block.Total(), "wrong total")
+	assert.Equal(t, 20*time.Millisecond, block.AverageLatency(), "wrong average latency")
+	assert.Equal(t, float64(1), s.Availability(), "wrong availability")
+	assert.True(t, s.Valid(), "wrong valid")
+}
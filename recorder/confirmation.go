@@ -0,0 +1,230 @@
+package recorder
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/clock"
+)
+
+const (
+	// broadcast transaction not found in any block is forgotten after this
+	pendingExpiredInterval = 24 * time.Hour
+)
+
+// TransactionBroadcast - transaction ID received from broadcast
+type TransactionBroadcast struct {
+	ID string
+}
+
+// BlockTransactions - transaction IDs of a block fetched from command port
+type BlockTransactions struct {
+	Number       uint64
+	GenerateTime time.Time
+	IDs          []string
+}
+
+// Confirmation - latency from broadcast received to block generated
+type Confirmation struct {
+	ID          string
+	BlockNumber uint64
+	Latency     time.Duration
+}
+
+// Unconfirmed - broadcast transaction not in any block after timeout
+type Unconfirmed struct {
+	ID  string
+	Age time.Duration
+}
+
+type pendingTransaction struct {
+	receivedAt time.Time
+	reported   bool
+}
+
+type confirmations struct {
+	sync.Mutex
+	confirmed []Confirmation
+	pending   map[string]*pendingTransaction
+	timeout   time.Duration
+}
+
+// ConfirmationSummary - confirmations since last summary, and transactions
+// newly exceeding confirm timeout
+type ConfirmationSummary struct {
+	Confirmed   []Confirmation
+	Pending     int
+	Timeout     time.Duration
+	Unconfirmed []Unconfirmed
+}
+
+func (c *ConfirmationSummary) String() string {
+	var str strings.Builder
+	str.WriteString(fmt.Sprintf(
+		"confirmed %d transactions, average latency %s, pending %d",
+		len(c.Confirmed),
+		c.AverageLatency(),
+		c.Pending,
+	))
+
+	if 0 < len(c.Unconfirmed) {
+		str.WriteString(fmt.Sprintf(", not confirmed after %s: ", c.Timeout))
+		for _, u := range c.Unconfirmed {
+			str.WriteString(fmt.Sprintf("%s (%s) ", u.ID, u.Age.Truncate(time.Second)))
+		}
+	}
+	return str.String()
+}
+
+// Valid - no transaction exceeds confirm timeout
+func (c *ConfirmationSummary) Valid() bool {
+	return 0 == len(c.Unconfirmed)
+}
+
+// AverageLatency - average latency of confirmed transactions
+func (c *ConfirmationSummary) AverageLatency() time.Duration {
+	if 0 == len(c.Confirmed) {
+		return time.Duration(0)
+	}
+
+	var total time.Duration
+	for _, confirmed := range c.Confirmed {
+		total += confirmed.Latency
+	}
+	return total / time.Duration(len(c.Confirmed))
+}
+
+// MaxLatency - maximum latency of confirmed transactions
+func (c *ConfirmationSummary) MaxLatency() time.Duration {
+	var max time.Duration
+	for _, confirmed := range c.Confirmed {
+		if confirmed.Latency > max {
+			max = confirmed.Latency
+		}
+	}
+	return max
+}
+
+// Add - add TransactionBroadcast or BlockTransactions
+func (c *confirmations) Add(t time.Time, args ...interface{}) {
+	if 0 == len(args) {
+		return
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	switch data := args[0].(type) {
+	case TransactionBroadcast:
+		if _, ok := c.pending[data.ID]; !ok {
+			c.pending[data.ID] = &pendingTransaction{receivedAt: t}
+		}
+
+	case BlockTransactions:
+		c.confirm(data)
+	}
+}
+
+func (c *confirmations) confirm(block BlockTransactions) {
+	for _, id := range block.IDs {
+		p, ok := c.pending[id]
+		if !ok {
+			continue
+		}
+
+		// block timestamp is in seconds, and clocks of node and monitor differ
+		latency := block.GenerateTime.Sub(p.receivedAt)
+		if 0 > latency {
+			latency = time.Duration(0)
+		}
+
+		c.confirmed = append(c.confirmed, Confirmation{
+			ID:          id,
+			BlockNumber: block.Number,
+			Latency:     latency,
+		})
+		delete(c.pending, id)
+	}
+}
+
+// PeriodicRemove - periodically remove transactions never confirmed
+func (c *confirmations) PeriodicRemove(args []interface{}) {
+	if 2 != len(args) {
+		fmt.Println("confirmations PeriodicRemove wrong arguments length")
+		return
+	}
+	clk := args[0].(clock.Clock)
+	shutdown := args[1].(<-chan struct{})
+	timer := clk.NewTimer(expiredTimeInterval)
+loop:
+	for {
+		select {
+		case <-shutdown:
+			break loop
+
+		case <-timer.C:
+			c.RemoveExpired()
+			timer.Reset(expiredTimeInterval)
+		}
+	}
+	fmt.Println("terminate confirmations PeriodicRemove")
+}
+
+// RemoveExpired - remove transactions never confirmed now
+func (c *confirmations) RemoveExpired() {
+	cleanupExpiredPending(c, currentTime())
+}
+
+func cleanupExpiredPending(c *confirmations, now time.Time) {
+	c.Lock()
+	defer c.Unlock()
+
+	for id, p := range c.pending {
+		if now.Sub(p.receivedAt) > pendingExpiredInterval {
+			delete(c.pending, id)
+		}
+	}
+}
+
+// Summary - confirmations since last summary, unconfirmed transactions are
+// reported once when exceeding timeout
+func (c *confirmations) Summary() SummaryOutput {
+	c.Lock()
+	defer c.Unlock()
+
+	now := currentTime()
+	unconfirmed := make([]Unconfirmed, 0)
+	for id, p := range c.pending {
+		age := now.Sub(p.receivedAt)
+		if p.reported || age < c.timeout {
+			continue
+		}
+		p.reported = true
+		unconfirmed = append(unconfirmed, Unconfirmed{
+			ID:  id,
+			Age: age,
+		})
+	}
+
+	summary := &ConfirmationSummary{
+		Confirmed:   c.confirmed,
+		Pending:     len(c.pending),
+		Timeout:     c.timeout,
+		Unconfirmed: unconfirmed,
+	}
+	c.confirmed = make([]Confirmation, 0)
+
+	return summary
+}
+
+// NewConfirmation - new transaction confirmation recorder, transactions not
+// confirmed after timeout are reported
+func NewConfirmation(timeout time.Duration) Recorder {
+	return &confirmations{
+		confirmed: make([]Confirmation, 0),
+		pending:   make(map[string]*pendingTransaction),
+		timeout:   timeout,
+	}
+}
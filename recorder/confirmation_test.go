@@ -0,0 +1,91 @@
+package recorder_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/recorder"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	confirmTimeout = 30 * time.Minute
+)
+
+func TestConfirmationSummaryWhenConfirmed(t *testing.T) {
+	r := recorder.NewConfirmation(confirmTimeout)
+	now := time.Now()
+
+	r.Add(now.Add(-3*time.Minute), recorder.TransactionBroadcast{ID: txID1})
+	r.Add(now.Add(-2*time.Minute), recorder.TransactionBroadcast{ID: txID2})
+	r.Add(now, recorder.BlockTransactions{
+		Number:       uint64(100),
+		GenerateTime: now.Add(-1 * time.Minute),
+		IDs:          []string{"foundation", txID1, txID2},
+	})
+
+	s := r.Summary().(*recorder.ConfirmationSummary)
+	assert.Equal(t, 2, len(s.Confirmed), "wrong confirmed count")
+	assert.Equal(t, 0, s.Pending, "wrong pending count")
+	assert.Equal(t, uint64(100), s.Confirmed[0].BlockNumber, "wrong block number")
+	assert.Equal(t, 2*time.Minute, s.Confirmed[0].Latency, "wrong latency")
+	assert.Equal(t, 1*time.Minute, s.Confirmed[1].Latency, "wrong latency")
+	assert.Equal(t, 90*time.Second, s.AverageLatency(), "wrong average latency")
+	assert.Equal(t, 2*time.Minute, s.MaxLatency(), "wrong max latency")
+	assert.True(t, s.Valid(), "wrong valid")
+
+	s = r.Summary().(*recorder.ConfirmationSummary)
+	assert.Equal(t, 0, len(s.Confirmed), "wrong confirmed not cleared")
+}
+
+func TestConfirmationSummaryWhenBlockBeforeBroadcast(t *testing.T) {
+	r := recorder.NewConfirmation(confirmTimeout)
+	now := time.Now()
+
+	r.Add(now, recorder.TransactionBroadcast{ID: txID1})
+	r.Add(now, recorder.BlockTransactions{
+		Number:       uint64(100),
+		GenerateTime: now.Add(-1 * time.Second),
+		IDs:          []string{txID1},
+	})
+
+	s := r.Summary().(*recorder.ConfirmationSummary)
+	assert.Equal(t, time.Duration(0), s.Confirmed[0].Latency, "wrong negative latency")
+}
+
+func TestConfirmationSummaryWhenTimeout(t *testing.T) {
+	r := recorder.NewConfirmation(confirmTimeout)
+	now := time.Now()
+
+	r.Add(now.Add(-1*time.Hour), recorder.TransactionBroadcast{ID: txID1})
+	r.Add(now, recorder.TransactionBroadcast{ID: txID2})
+
+	s := r.Summary().(*recorder.ConfirmationSummary)
+	assert.False(t, s.Valid(), "wrong valid")
+	assert.Equal(t, 1, len(s.Unconfirmed), "wrong unconfirmed count")
+	assert.Equal(t, txID1, s.Unconfirmed[0].ID, "wrong unconfirmed ID")
+	assert.Equal(t, 2, s.Pending, "wrong pending count")
+	assert.Contains(t, s.String(), txID1, "wrong string")
+
+	s = r.Summary().(*recorder.ConfirmationSummary)
+	assert.True(t, s.Valid(), "wrong reported again")
+}
+
+func TestConfirmationRemovePeriodically(t *testing.T) {
+	ctl, mock := setupTestClock(t)
+	defer ctl.Finish()
+
+	mock.EXPECT().NewTimer(gomock.Any()).Return(time.NewTimer(1)).Times(1)
+
+	r := recorder.NewConfirmation(confirmTimeout)
+	now := time.Now()
+	r.Add(now.Add(-25*time.Hour), recorder.TransactionBroadcast{ID: txID1})
+	r.Add(now, recorder.TransactionBroadcast{ID: txID2})
+
+	go r.PeriodicRemove([]interface{}{mock, ctx.Done()})
+	<-time.After(10 * time.Millisecond)
+
+	s := r.Summary().(*recorder.ConfirmationSummary)
+	assert.Equal(t, 1, s.Pending, "wrong pending count")
+}
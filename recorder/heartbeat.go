@@ -92,15 +92,20 @@ loop:
 		case <-shutdown:
 			break loop
 		case <-timer:
-			cleanupExpiredHeartbeat(h)
+			h.RemoveExpired()
 			timer = c.After(time.Duration(intervalSecond) * time.Second)
 		}
 	}
 	fmt.Println("terminate heartbeat PeriodicRemove")
 }
 
+// RemoveExpired - clean expired heartbeat record now
+func (h *heartbeat) RemoveExpired() {
+	cleanupExpiredHeartbeat(h)
+}
+
 func cleanupExpiredHeartbeat(h *heartbeat) {
-	now := time.Now()
+	now := currentTime()
 
 	h.Lock()
 
@@ -155,7 +160,7 @@ func durationFromEarliestReceive(h *heartbeat) (time.Duration, time.Time) {
 }
 
 func (h *heartbeat) chooseClosestLatestReceiveTime(latestReceivedTime time.Time) time.Time {
-	now := time.Now()
+	now := currentTime()
 	if now.Sub(latestReceivedTime).Seconds() >= intervalSecond {
 		return now
 	}
@@ -184,7 +189,7 @@ func (h *heartbeat) droprate(actualReceived uint16, duration time.Duration) floa
 func NewHeartbeat(interval float64, t tasks.Tasks, ctx context.Context) Recorder {
 	h := &heartbeat{
 		data:     make(map[receivedAt]expiredAt),
-		earliest: time.Now(),
+		earliest: currentTime(),
 	}
 	fullCycleReceivedCount = math.Floor(expiredTimeInterval.Seconds() / interval)
 	intervalSecond = interval
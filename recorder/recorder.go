@@ -17,9 +17,11 @@ type Adder interface {
 	Add(time.Time, ...interface{})
 }
 
-// PeriodicRemover - interface for periodically removing outdated records
+// PeriodicRemover - interface for periodically removing outdated records,
+// replay removes them on its own clock
 type PeriodicRemover interface {
 	PeriodicRemove(args []interface{})
+	RemoveExpired()
 }
 
 // Summarizer - interface for summarizing status of records
@@ -46,3 +48,11 @@ const (
 	totalReceivedCount  = int(expiredTimeInterval / time.Minute)
 	indexNotFound       = -1
 )
+
+// replaced when replaying captured broadcasts
+var currentTime = time.Now
+
+// SetTimeSource - set function recorders use to get current time
+func SetTimeSource(source func() time.Time) {
+	currentTime = source
+}
@@ -81,14 +81,19 @@ loop:
 			break loop
 
 		case <-c.After(expiredTimeInterval):
-			cleanupExpiredTransaction(t)
+			t.RemoveExpired()
 		}
 	}
 	fmt.Println("terminate transaction PeriodicRemove")
 }
 
+// RemoveExpired - clean expired transaction now
+func (t *transactions) RemoveExpired() {
+	cleanupExpiredTransaction(t)
+}
+
 func cleanupExpiredTransaction(t *transactions) {
-	if t.firstItemReceivedTime.After(time.Now().Add(-1 * expiredTimeInterval)) {
+	if t.firstItemReceivedTime.After(currentTime().Add(-1 * expiredTimeInterval)) {
 		return
 	}
 
@@ -144,14 +149,14 @@ func (t *transactions) Summary() SummaryOutput {
 	}
 	return &TransactionSummary{
 		Droprate:      dropRate,
-		Duration:      time.Now().Sub(t.firstItemReceivedTime),
+		Duration:      currentTime().Sub(t.firstItemReceivedTime),
 		received:      t.received,
 		ReceivedCount: int(receivedCountFromPrevTwoHour(t.data)),
 	}
 }
 
 func expectedCount(t *transactions) float64 {
-	expectedCount := float64(time.Now().Sub(t.firstItemReceivedTime) / time.Minute)
+	expectedCount := float64(currentTime().Sub(t.firstItemReceivedTime) / time.Minute)
 	if 0 == expectedCount {
 		return 1
 	}
@@ -178,6 +183,6 @@ func receivedCountFromPrevTwoHour(data [totalReceivedCount]bool) float64 {
 // NewTransaction - new transaction
 func NewTransaction() Recorder {
 	return &transactions{
-		earliest: time.Now(),
+		earliest: currentTime(),
 	}
 }
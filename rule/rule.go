@@ -0,0 +1,270 @@
+package rule
+
+import (
+	"fmt"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/configuration"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/fault"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/message"
+)
+
+// metrics of rule
+const (
+	// DropRate - transaction drop rate between 0 and 1
+	DropRate = "drop-rate"
+
+	// Lag - blocks behind best height of same chain
+	Lag = "lag"
+
+	// ForkDepth - blocks of deepest fork in broadcast blocks
+	ForkDepth = "fork-depth"
+
+	// ConfirmTime - minutes from block generated to block broadcast received
+	ConfirmTime = "confirm-time"
+
+	// DisconnectTime - minutes since last broadcast received
+	DisconnectTime = "disconnect-time"
+
+	// HeartbeatDropRate - heartbeat drop rate between 0 and 1
+	HeartbeatDropRate = "heartbeat-drop-rate"
+
+	// MissingBlocks - block numbers skipped in broadcast blocks
+	MissingBlocks = "missing-blocks"
+)
+
+var (
+	metrics = map[string]struct{}{
+		DropRate:          {},
+		Lag:               {},
+		ForkDepth:         {},
+		ConfirmTime:       {},
+		DisconnectTime:    {},
+		HeartbeatDropRate: {},
+		MissingBlocks:     {},
+	}
+
+	comparisons = map[string]func(float64, float64) bool{
+		">":  func(v float64, t float64) bool { return v > t },
+		">=": func(v float64, t float64) bool { return v >= t },
+		"<":  func(v float64, t float64) bool { return v < t },
+		"<=": func(v float64, t float64) bool { return v <= t },
+		"==": func(v float64, t float64) bool { return v == t },
+		"!=": func(v float64, t float64) bool { return v != t },
+	}
+
+	severities = map[string]struct{}{
+		"":         {},
+		"critical": {},
+		"warning":  {},
+		"info":     {},
+	}
+)
+
+// Result - outcome of rule on node, condition of alert is rule name, value
+// and lasting are kept for message templates
+type Result struct {
+	configuration.RuleConfig
+	Firing  bool
+	Message string
+	Value   float64
+	Lasting time.Duration
+}
+
+// Rules - evaluate metric values of nodes against rules
+type Rules interface {
+	Covers(string, string) bool
+	Evaluate(string, string, float64, time.Time) []Result
+	Reset(string, string) []Result
+}
+
+type rule struct {
+	configuration.RuleConfig
+	compare func(float64, float64) bool
+	clear   float64 // threshold of firing rule, shifted by hysteresis
+	since   map[string]time.Time
+	firing  map[string]bool
+}
+
+type rules struct {
+	sync.Mutex
+	rules []*rule
+}
+
+// New - rules of config, name, metric, comparison, severity and node
+// selectors are validated
+func New(configs []configuration.RuleConfig) (Rules, error) {
+	names := make(map[string]struct{})
+	result := &rules{rules: make([]*rule, 0, len(configs))}
+
+	for _, c := range configs {
+		if err := validate(c, names); nil != err {
+			fmt.Printf("rule %q with error: %s\n", c.Name, err)
+			return nil, err
+		}
+		names[c.Name] = struct{}{}
+
+		result.rules = append(result.rules, &rule{
+			RuleConfig: c,
+			compare:    comparisons[c.Comparison],
+			clear:      clearThreshold(c),
+			since:      make(map[string]time.Time),
+			firing:     make(map[string]bool),
+		})
+	}
+	return result, nil
+}
+
+func validate(c configuration.RuleConfig, names map[string]struct{}) error {
+	if _, ok := names[c.Name]; ok || "" == c.Name {
+		return fault.InvalidRuleName
+	}
+
+	if _, ok := metrics[c.Metric]; !ok {
+		return fault.InvalidRuleMetric
+	}
+
+	if _, ok := comparisons[c.Comparison]; !ok {
+		return fault.InvalidRuleComparison
+	}
+
+	if 0 > c.Hysteresis || (0 < c.Hysteresis && ("==" == c.Comparison || "!=" == c.Comparison)) {
+		return fault.InvalidRuleHysteresis
+	}
+
+	if _, ok := severities[c.Severity]; !ok {
+		return fault.InvalidRuleSeverity
+	}
+
+	for _, pattern := range c.Nodes {
+		if _, err := path.Match(pattern, ""); nil != err {
+			return err
+		}
+	}
+	return nil
+}
+
+// clearThreshold - threshold moved back by hysteresis, e.g. drop-rate > 0.1
+// with hysteresis 0.02 keeps firing until rate is not above 0.08
+func clearThreshold(c configuration.RuleConfig) float64 {
+	switch c.Comparison {
+	case ">", ">=":
+		return c.Threshold - c.Hysteresis
+	case "<", "<=":
+		return c.Threshold + c.Hysteresis
+	default:
+		return c.Threshold
+	}
+}
+
+// Covers - any rule of metric selects node
+func (rs *rules) Covers(node string, metric string) bool {
+	rs.Lock()
+	defer rs.Unlock()
+
+	for _, r := range rs.rules {
+		if r.Metric == metric && r.selects(node) {
+			return true
+		}
+	}
+	return false
+}
+
+// Evaluate - results of rules of metric selecting node, rule fires when
+// comparison holds for its duration, and keeps firing until comparison
+// fails against threshold moved back by hysteresis
+func (rs *rules) Evaluate(node string, metric string, value float64, now time.Time) []Result {
+	rs.Lock()
+	defer rs.Unlock()
+
+	results := make([]Result, 0)
+	for _, r := range rs.rules {
+		if r.Metric != metric || !r.selects(node) {
+			continue
+		}
+
+		threshold := r.Threshold
+		if r.firing[node] {
+			threshold = r.clear
+		}
+
+		if !r.compare(value, threshold) {
+			delete(r.since, node)
+			delete(r.firing, node)
+			results = append(results, Result{RuleConfig: r.RuleConfig, Value: value})
+			continue
+		}
+
+		since, ok := r.since[node]
+		if !ok {
+			since = now
+			r.since[node] = since
+		}
+
+		lasting := now.Sub(since)
+		firing := lasting >= time.Duration(r.DurationMinute)*time.Minute
+		if firing {
+			r.firing[node] = true
+		}
+
+		results = append(results, Result{
+			RuleConfig: r.RuleConfig,
+			Firing:     firing,
+			Message:    r.message(value, threshold, lasting),
+			Value:      value,
+			Lasting:    lasting,
+		})
+	}
+	return results
+}
+
+// Reset - forget rules of metric selecting node, duration of comparison
+// starts over at next evaluation, results are not firing
+func (rs *rules) Reset(node string, metric string) []Result {
+	rs.Lock()
+	defer rs.Unlock()
+
+	results := make([]Result, 0)
+	for _, r := range rs.rules {
+		if r.Metric != metric || !r.selects(node) {
+			continue
+		}
+
+		delete(r.since, node)
+		delete(r.firing, node)
+		results = append(results, Result{RuleConfig: r.RuleConfig})
+	}
+	return results
+}
+
+// selects - empty selectors select all nodes
+func (r *rule) selects(node string) bool {
+	if 0 == len(r.Nodes) {
+		return true
+	}
+
+	for _, pattern := range r.Nodes {
+		if matched, _ := path.Match(pattern, node); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// message - threshold of firing rule is moved back by hysteresis
+func (r *rule) message(value float64, threshold float64, lasting time.Duration) string {
+	msg := fmt.Sprintf(
+		"%s %s %s %s",
+		r.Metric,
+		message.Round(value, 4),
+		r.Comparison,
+		message.Round(threshold, 4),
+	)
+
+	if 0 < r.DurationMinute || 0 < lasting {
+		msg = fmt.Sprintf("%s for %s", msg, lasting.Truncate(time.Second))
+	}
+	return msg
+}
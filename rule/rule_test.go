@@ -0,0 +1,130 @@
+package rule_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/configuration"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/fault"
+	"github.com/jamieabc/bitmarkd-broadcast-monitor/rule"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewWhenInvalid(t *testing.T) {
+	valid := configuration.RuleConfig{Name: "a", Metric: rule.Lag, Comparison: ">"}
+
+	tests := []struct {
+		configs  []configuration.RuleConfig
+		expected error
+	}{
+		{[]configuration.RuleConfig{valid, valid}, fault.InvalidRuleName},
+		{[]configuration.RuleConfig{{Metric: rule.Lag, Comparison: ">"}}, fault.InvalidRuleName},
+		{[]configuration.RuleConfig{{Name: "a", Metric: "height", Comparison: ">"}}, fault.InvalidRuleMetric},
+		{[]configuration.RuleConfig{{Name: "a", Metric: rule.Lag, Comparison: "=>"}}, fault.InvalidRuleComparison},
+		{[]configuration.RuleConfig{{Name: "a", Metric: rule.Lag, Comparison: ">", Severity: "fatal"}}, fault.InvalidRuleSeverity},
+		{[]configuration.RuleConfig{{Name: "a", Metric: rule.Lag, Comparison: ">", Hysteresis: -1}}, fault.InvalidRuleHysteresis},
+		{[]configuration.RuleConfig{{Name: "a", Metric: rule.Lag, Comparison: "==", Hysteresis: 1}}, fault.InvalidRuleHysteresis},
+	}
+
+	for _, test := range tests {
+		_, err := rule.New(test.configs)
+		assert.Equal(t, test.expected, err, "wrong error")
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	rs, err := rule.New([]configuration.RuleConfig{
+		{Name: "drop", Metric: rule.DropRate, Comparison: ">", Threshold: 0.1, Severity: "warning"},
+		{Name: "lag", Metric: rule.Lag, Comparison: ">=", Threshold: 3},
+	})
+	assert.Nil(t, err, "wrong error")
+
+	now := time.Now()
+	results := rs.Evaluate("a", rule.DropRate, 0.25, now)
+	assert.Equal(t, 1, len(results), "wrong result count")
+	assert.Equal(t, "drop", results[0].Name, "wrong rule")
+	assert.True(t, results[0].Firing, "wrong not firing")
+	assert.Equal(t, "drop-rate 0.25 > 0.1", results[0].Message, "wrong message")
+
+	results = rs.Evaluate("a", rule.DropRate, 0.05, now)
+	assert.False(t, results[0].Firing, "wrong firing")
+}
+
+func TestCovers(t *testing.T) {
+	rs, _ := rule.New([]configuration.RuleConfig{
+		{Name: "lag", Metric: rule.Lag, Comparison: ">", Threshold: 3, Nodes: []string{"mainnet-*"}},
+	})
+
+	assert.True(t, rs.Covers("mainnet-1", rule.Lag), "wrong not covered")
+	assert.False(t, rs.Covers("testnet-1", rule.Lag), "wrong covered of unselected node")
+	assert.False(t, rs.Covers("mainnet-1", rule.ForkDepth), "wrong covered of other metric")
+}
+
+func TestEvaluateWithDuration(t *testing.T) {
+	rs, _ := rule.New([]configuration.RuleConfig{
+		{Name: "lag", Metric: rule.Lag, Comparison: ">", Threshold: 3, DurationMinute: 5},
+	})
+
+	now := time.Now()
+	assert.False(t, rs.Evaluate("a", rule.Lag, 4, now)[0].Firing, "wrong firing at start")
+	assert.False(t, rs.Evaluate("a", rule.Lag, 4, now.Add(4*time.Minute))[0].Firing, "wrong firing before duration")
+	assert.False(t, rs.Evaluate("b", rule.Lag, 4, now.Add(5*time.Minute))[0].Firing, "wrong firing of other node")
+
+	result := rs.Evaluate("a", rule.Lag, 5, now.Add(5*time.Minute))[0]
+	assert.True(t, result.Firing, "wrong not firing after duration")
+	assert.Equal(t, "lag 5 > 3 for 5m0s", result.Message, "wrong message")
+	assert.Equal(t, float64(5), result.Value, "wrong value")
+	assert.Equal(t, 5*time.Minute, result.Lasting, "wrong lasting")
+
+	assert.False(t, rs.Evaluate("a", rule.Lag, 2, now.Add(6*time.Minute))[0].Firing, "wrong firing when cleared")
+	assert.False(t, rs.Evaluate("a", rule.Lag, 4, now.Add(7*time.Minute))[0].Firing, "wrong duration not restarted")
+}
+
+func TestEvaluateWithHysteresis(t *testing.T) {
+	rs, _ := rule.New([]configuration.RuleConfig{
+		{Name: "drop", Metric: rule.DropRate, Comparison: ">", Threshold: 0.1, Hysteresis: 0.02},
+	})
+
+	now := time.Now()
+	assert.False(t, rs.Evaluate("a", rule.DropRate, 0.09, now)[0].Firing, "wrong firing below threshold")
+	assert.True(t, rs.Evaluate("a", rule.DropRate, 0.11, now)[0].Firing, "wrong not firing above threshold")
+
+	result := rs.Evaluate("a", rule.DropRate, 0.09, now.Add(2*time.Minute))[0]
+	assert.True(t, result.Firing, "wrong cleared within hysteresis")
+	assert.Equal(t, 2*time.Minute, result.Lasting, "wrong lasting")
+	assert.Equal(t, "drop-rate 0.09 > 0.08 for 2m0s", result.Message, "wrong message")
+
+	assert.False(t, rs.Evaluate("a", rule.DropRate, 0.08, now.Add(4*time.Minute))[0].Firing, "wrong firing below hysteresis")
+	assert.False(t, rs.Evaluate("a", rule.DropRate, 0.09, now.Add(6*time.Minute))[0].Firing, "wrong firing after cleared")
+}
+
+func TestEvaluateNodeSelectors(t *testing.T) {
+	rs, _ := rule.New([]configuration.RuleConfig{
+		{Name: "fork", Metric: rule.ForkDepth, Comparison: ">", Threshold: 0, Nodes: []string{"main-*", "backup"}},
+	})
+
+	now := time.Now()
+	assert.Equal(t, 1, len(rs.Evaluate("main-1", rule.ForkDepth, 2, now)), "wrong glob selector")
+	assert.Equal(t, 1, len(rs.Evaluate("backup", rule.ForkDepth, 2, now)), "wrong name selector")
+	assert.Equal(t, 0, len(rs.Evaluate("test-1", rule.ForkDepth, 2, now)), "wrong node not selected")
+	assert.Equal(t, 0, len(rs.Evaluate("main-1", rule.Lag, 2, now)), "wrong metric")
+}
+
+func TestReset(t *testing.T) {
+	rs, _ := rule.New([]configuration.RuleConfig{
+		{Name: "drop", Metric: rule.DropRate, Comparison: ">", Threshold: 0.1, DurationMinute: 4},
+	})
+
+	now := time.Now()
+	rs.Evaluate("a", rule.DropRate, 0.2, now)
+	assert.True(t, rs.Evaluate("a", rule.DropRate, 0.2, now.Add(4*time.Minute))[0].Firing, "wrong not firing")
+
+	results := rs.Reset("a", rule.DropRate)
+	assert.Equal(t, 1, len(results), "wrong result count")
+	assert.False(t, results[0].Firing, "wrong firing after reset")
+	assert.Equal(t, 0, len(rs.Reset("a", rule.Lag)), "wrong rule of other metric")
+
+	result := rs.Evaluate("a", rule.DropRate, 0.2, now.Add(10*time.Minute))[0]
+	assert.False(t, result.Firing, "wrong stale duration after reset")
+	assert.Equal(t, time.Duration(0), result.Lasting, "wrong lasting after reset")
+}